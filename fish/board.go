@@ -0,0 +1,736 @@
+package fish
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type int_array []int
+type piece byte
+type mailbox [120]piece
+type piece_table []int_array
+
+// Move is a move of a Position, as ParseMove and LegalMoves give them.
+type Move struct {
+	from, to int
+	prom     int // promotion piece, 0 for none
+}
+
+const FEN_INITIAL = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+const piece_p = 0
+const piece_n = 1
+const piece_b = 2
+const piece_r = 3
+const piece_q = 4
+const piece_k = 5
+const piece_is_lower = 1 << 3
+const piece_is_empty = 1 << 4
+const piece_is_invalid = 1 << 5
+const piece_not_piece = piece_is_empty | piece_is_invalid
+
+const a1, h1, a8, h8 = 91, 98, 21, 28
+
+const north, east, south, west = -10, 1, 10, -1
+
+// Position is a board seen from the side to move, which is always at the
+// bottom: after every move it is rotated and the colors swapped.
+type Position struct {
+	board    mailbox
+	score    int // taper(mg, eg, phase)
+	wc, bc   [2]bool
+	ep, kp   int
+	halfmove int // plies since the last capture or pawn move
+	ply      int // plies since the start of the game, even when white is to move
+
+	// history holds the hashes of the positions since the last capture or
+	// pawn move, oldest first. Only Play keeps it, the search doesn't.
+	history []uint64
+
+	// Zobrist hashes of this board and of the rotated one, see zobrist.go.
+	// They leave out the move counters.
+	hash, rhash uint64
+
+	// The same for the pawns alone, the key of the pawn hash in terms.go
+	pawn_hash, pawn_rhash uint64
+
+	// Middlegame and endgame scores, and the game phase, see eval.go
+	mg, eg, phase int
+}
+
+func (board *mailbox) contains(p piece) bool {
+	for _, v := range board {
+		if v == p {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p piece) isupper() bool {
+	return (p&piece_is_lower) == 0 && (p&piece_not_piece) == 0
+}
+
+func (p piece) islower() bool {
+	return (p&piece_is_lower) != 0 && (p&piece_not_piece) == 0
+}
+
+func (p piece) is_invalid_space() bool {
+	return p == piece_is_invalid
+}
+
+// is_side tells whether p belongs to the upper case side, for 0, or to the
+// lower case one, for piece_is_lower.
+func (p piece) is_side(side piece) bool {
+	return p&piece_not_piece == 0 && p&piece_is_lower == side
+}
+
+func (p piece) is_pawn() bool {
+	return p&^piece_is_lower == piece_p
+}
+
+func (p piece) swapcase() piece {
+	if (p & piece_not_piece) != 0 {
+		return p
+	}
+
+	return p ^ piece_is_lower
+}
+
+func (p piece) String() string {
+	switch p {
+	case piece_is_empty:
+		return "."
+	case piece_is_invalid:
+		return " "
+	case piece_p:
+		return "P"
+	case piece_n:
+		return "N"
+	case piece_b:
+		return "B"
+	case piece_r:
+		return "R"
+	case piece_q:
+		return "Q"
+	case piece_k:
+		return "K"
+	case piece_p | piece_is_lower:
+		return "p"
+	case piece_n | piece_is_lower:
+		return "n"
+	case piece_b | piece_is_lower:
+		return "b"
+	case piece_r | piece_is_lower:
+		return "r"
+	case piece_q | piece_is_lower:
+		return "q"
+	case piece_k | piece_is_lower:
+		return "k"
+	}
+
+	return "?"
+}
+
+// make_piece reads a piece letter of a FEN, or '.' and ' ' for the other
+// squares of the board. ok is false for any other byte.
+func make_piece(b byte) (p piece, ok bool) {
+	switch b {
+	case '.':
+		return piece_is_empty, true
+	case ' ':
+		return piece_is_invalid, true
+	case 'P':
+		return piece_p, true
+	case 'N':
+		return piece_n, true
+	case 'B':
+		return piece_b, true
+	case 'R':
+		return piece_r, true
+	case 'Q':
+		return piece_q, true
+	case 'K':
+		return piece_k, true
+	case 'p':
+		return piece_p | piece_is_lower, true
+	case 'n':
+		return piece_n | piece_is_lower, true
+	case 'b':
+		return piece_b | piece_is_lower, true
+	case 'r':
+		return piece_r | piece_is_lower, true
+	case 'q':
+		return piece_q | piece_is_lower, true
+	case 'k':
+		return piece_k | piece_is_lower, true
+	}
+
+	return 0, false
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func max(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
+
+func min(x, y int) int {
+	if x < y {
+		return x
+	}
+	return y
+}
+
+func (self *Position) white_turn() bool {
+	return self.ply%2 == 0
+}
+
+// white_view returns the position with white at the bottom of the board,
+// whichever side is to move.
+func (self *Position) white_view() *Position {
+	if self.white_turn() {
+		return self
+	}
+	return self.rotate()
+}
+
+// String draws the board from white's side, followed by the FEN.
+func (self *Position) String() string {
+	view := self.white_view()
+	line := 8
+	var sb strings.Builder
+
+	sb.WriteString("     a b c d e f g h\n")
+	for i := a8; i <= h1; i += south {
+		fmt.Fprintf(&sb, "  %d  ", line)
+		for j := 0; j < 8; j++ {
+			fmt.Fprintf(&sb, "%s ", view.board[i+j])
+		}
+		fmt.Fprintf(&sb, " %d\n", line)
+		line--
+	}
+	sb.WriteString("     a b c d e f g h\n\n")
+	fmt.Fprintf(&sb, "  %s\n\n", self.fen())
+
+	return sb.String()
+}
+
+func (self *Position) fen() string {
+	view := self.white_view()
+	var sb strings.Builder
+
+	for i := a8; i <= h1; i += south {
+		empty := 0
+		for j := 0; j < 8; j++ {
+			p := view.board[i+j]
+			if p == piece_is_empty {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				sb.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+			sb.WriteString(p.String())
+		}
+		if empty > 0 {
+			sb.WriteString(strconv.Itoa(empty))
+		}
+		if i != a1 {
+			sb.WriteString("/")
+		}
+	}
+
+	if self.white_turn() {
+		sb.WriteString(" w ")
+	} else {
+		sb.WriteString(" b ")
+	}
+
+	castling := ""
+	if view.wc[1] {
+		castling += "K"
+	}
+	if view.wc[0] {
+		castling += "Q"
+	}
+	if view.bc[0] {
+		castling += "k"
+	}
+	if view.bc[1] {
+		castling += "q"
+	}
+	if castling == "" {
+		castling = "-"
+	}
+	sb.WriteString(castling)
+
+	if view.ep != 0 {
+		sb.WriteString(" " + squareName(view.ep))
+	} else {
+		sb.WriteString(" -")
+	}
+
+	fmt.Fprintf(&sb, " %d %d", self.halfmove, self.ply/2+1)
+
+	return sb.String()
+}
+
+func (self *Position) rotate() *Position {
+	pos := &Position{}
+
+	pos.board = self.board
+	pos.score = -self.score
+	pos.mg = -self.mg
+	pos.eg = -self.eg
+	pos.phase = self.phase
+	pos.wc = self.bc
+	pos.bc = self.wc
+	pos.ep = 0
+	pos.kp = 0
+	pos.halfmove = self.halfmove
+	pos.ply = self.ply
+	pos.hash, pos.rhash = self.rhash, self.hash
+	pos.pawn_hash, pos.pawn_rhash = self.pawn_rhash, self.pawn_hash
+
+	if self.ep != 0 {
+		pos.ep = 119 - self.ep
+	}
+
+	if self.kp != 0 {
+		pos.kp = 119 - self.kp
+	}
+
+	// rotate & swap case
+	pos.board[21], pos.board[98] = pos.board[98].swapcase(), pos.board[21].swapcase()
+	pos.board[22], pos.board[97] = pos.board[97].swapcase(), pos.board[22].swapcase()
+	pos.board[23], pos.board[96] = pos.board[96].swapcase(), pos.board[23].swapcase()
+	pos.board[24], pos.board[95] = pos.board[95].swapcase(), pos.board[24].swapcase()
+	pos.board[25], pos.board[94] = pos.board[94].swapcase(), pos.board[25].swapcase()
+	pos.board[26], pos.board[93] = pos.board[93].swapcase(), pos.board[26].swapcase()
+	pos.board[27], pos.board[92] = pos.board[92].swapcase(), pos.board[27].swapcase()
+	pos.board[28], pos.board[91] = pos.board[91].swapcase(), pos.board[28].swapcase()
+	pos.board[31], pos.board[88] = pos.board[88].swapcase(), pos.board[31].swapcase()
+	pos.board[32], pos.board[87] = pos.board[87].swapcase(), pos.board[32].swapcase()
+	pos.board[33], pos.board[86] = pos.board[86].swapcase(), pos.board[33].swapcase()
+	pos.board[34], pos.board[85] = pos.board[85].swapcase(), pos.board[34].swapcase()
+	pos.board[35], pos.board[84] = pos.board[84].swapcase(), pos.board[35].swapcase()
+	pos.board[36], pos.board[83] = pos.board[83].swapcase(), pos.board[36].swapcase()
+	pos.board[37], pos.board[82] = pos.board[82].swapcase(), pos.board[37].swapcase()
+	pos.board[38], pos.board[81] = pos.board[81].swapcase(), pos.board[38].swapcase()
+	pos.board[41], pos.board[78] = pos.board[78].swapcase(), pos.board[41].swapcase()
+	pos.board[42], pos.board[77] = pos.board[77].swapcase(), pos.board[42].swapcase()
+	pos.board[43], pos.board[76] = pos.board[76].swapcase(), pos.board[43].swapcase()
+	pos.board[44], pos.board[75] = pos.board[75].swapcase(), pos.board[44].swapcase()
+	pos.board[45], pos.board[74] = pos.board[74].swapcase(), pos.board[45].swapcase()
+	pos.board[46], pos.board[73] = pos.board[73].swapcase(), pos.board[46].swapcase()
+	pos.board[47], pos.board[72] = pos.board[72].swapcase(), pos.board[47].swapcase()
+	pos.board[48], pos.board[71] = pos.board[71].swapcase(), pos.board[48].swapcase()
+	pos.board[51], pos.board[68] = pos.board[68].swapcase(), pos.board[51].swapcase()
+	pos.board[52], pos.board[67] = pos.board[67].swapcase(), pos.board[52].swapcase()
+	pos.board[53], pos.board[66] = pos.board[66].swapcase(), pos.board[53].swapcase()
+	pos.board[54], pos.board[65] = pos.board[65].swapcase(), pos.board[54].swapcase()
+	pos.board[55], pos.board[64] = pos.board[64].swapcase(), pos.board[55].swapcase()
+	pos.board[56], pos.board[63] = pos.board[63].swapcase(), pos.board[56].swapcase()
+	pos.board[57], pos.board[62] = pos.board[62].swapcase(), pos.board[57].swapcase()
+	pos.board[58], pos.board[61] = pos.board[61].swapcase(), pos.board[58].swapcase()
+
+	return pos
+}
+
+func (self *Position) nullmove() *Position {
+	pos := self.rotate()
+	h, r := zobrist_rights(pos.wc, pos.bc, pos.ep, pos.kp)
+	pos.hash ^= h
+	pos.rhash ^= r
+	pos.ep = 0
+	pos.kp = 0
+	h, r = zobrist_rights(pos.wc, pos.bc, 0, 0)
+	pos.hash ^= h
+	pos.rhash ^= r
+	// Repetitions across a null move are not real ones
+	pos.halfmove = 0
+	pos.ply++
+	return pos
+}
+
+func (self *Position) move(move Move) *Position {
+	i, j := move.from, move.to
+	p, q := self.board[i], self.board[j]
+	board := self.board
+	wc, bc, ep, kp := self.wc, self.bc, 0, 0
+	mg, eg, phase := self.deltas(move)
+	mg += self.mg
+	eg += self.eg
+	phase += self.phase
+	halfmove := self.halfmove + 1
+
+	hash, rhash := zobrist_rights(wc, bc, self.ep, self.kp)
+	hash ^= self.hash
+	rhash ^= self.rhash
+	pawn_hash, pawn_rhash := self.pawn_hash, self.pawn_rhash
+	put := func(sq int, p piece) {
+		h_old, r_old := zobrist_square(board[sq], sq)
+		h_new, r_new := zobrist_square(p, sq)
+		hash ^= h_old ^ h_new
+		rhash ^= r_old ^ r_new
+		board[sq] = p
+	}
+	// The pawn hashes only change when a pawn moves or is taken
+	put_pawn := func(sq int, p piece) {
+		h, r := zobrist_square(p, sq)
+		pawn_hash ^= h
+		pawn_rhash ^= r
+	}
+	if q.is_pawn() {
+		put_pawn(j, q)
+	}
+
+	put(j, board[i])
+	put(i, piece_is_empty)
+
+	if i == a1 {
+		wc = [2]bool{false, wc[1]}
+	}
+	if i == h1 {
+		wc = [2]bool{wc[0], false}
+	}
+	if j == a8 {
+		bc = [2]bool{bc[0], false}
+	}
+	if j == h8 {
+		bc = [2]bool{false, bc[1]}
+	}
+
+	if p == piece_k {
+		wc = [2]bool{false, false}
+		if abs(j-i) == 2 {
+			kp = (i + j) / 2
+			if j < i {
+				put(a1, piece_is_empty)
+			} else {
+				put(h1, piece_is_empty)
+			}
+			put(kp, piece_r)
+		}
+	}
+
+	if p == piece_p || q.islower() {
+		halfmove = 0
+	}
+
+	if p == piece_p {
+		put_pawn(i, p)
+		if a8 <= j && j <= h8 {
+			put(j, move.promotion())
+		} else {
+			put_pawn(j, p)
+		}
+		if j-i == 2*north {
+			ep = i + north
+		}
+		if j == self.ep {
+			put_pawn(j+south, board[j+south])
+			put(j+south, piece_is_empty)
+		}
+	}
+
+	h, r := zobrist_rights(wc, bc, ep, kp)
+	hash ^= h
+	rhash ^= r
+
+	position := Position{board: board, score: taper(mg, eg, phase), wc: wc, bc: bc, ep: ep, kp: kp, halfmove: halfmove, ply: self.ply + 1, hash: hash, rhash: rhash, pawn_hash: pawn_hash, pawn_rhash: pawn_rhash, mg: mg, eg: eg, phase: phase}
+	return position.rotate()
+}
+
+func squareName(sq int) string {
+	sq -= a8
+	return fmt.Sprintf("%c%d", sq%10+'a', 8-sq/10)
+}
+
+func (m Move) String() string {
+	if m.prom != 0 {
+		return squareName(m.from) + squareName(m.to) + strings.ToLower(piece(m.prom).String())
+	}
+	return squareName(m.from) + squareName(m.to)
+}
+
+// promotion is the piece a pawn reaching the last rank turns into. Moves
+// without one, from sloppy input, promote to a queen.
+func (m Move) promotion() piece {
+	if m.prom == 0 {
+		return piece_q
+	}
+	return piece(m.prom)
+}
+
+func (m Move) rotate() Move {
+	return Move{119 - m.from, 119 - m.to, m.prom}
+}
+
+func parseMove(str string) (Move, bool) {
+	str = strings.TrimSpace(str)
+	if len(str) != 4 && len(str) != 5 {
+		return Move{}, false
+	}
+
+	from, from_ok := parseSquare(str[0:2])
+	to, to_ok := parseSquare(str[2:4])
+
+	if !from_ok || !to_ok {
+		return Move{}, false
+	}
+
+	if len(str) == 5 {
+		switch str[4] {
+		case 'q', 'n', 'r', 'b':
+			p, _ := make_piece(str[4])
+			return Move{from, to, int(p.swapcase())}, true
+		}
+		return Move{}, false
+	}
+
+	return Move{from: from, to: to}, true
+}
+
+func parseSquare(str string) (int, bool) {
+	if len(str) != 2 {
+		return 0, false
+	}
+
+	file := int(str[0]) - 'a'
+	rank := int(str[1]) - '1'
+
+	if file < 0 || file > 7 || rank < 0 || rank > 7 {
+		return 0, false
+	}
+
+	return a1 + file*east + rank*north, true
+}
+
+func parseFEN(fen string) (*Position, error) {
+	parts := strings.Fields(fen)
+	if len(parts) < 4 || len(parts) > 6 {
+		return nil, fmt.Errorf("FEN needs 4 to 6 fields [%s]", fen)
+	}
+	board, color, castling, enpas := parts[0], parts[1], parts[2], parts[3]
+
+	var parsed_board mailbox
+	for i := range parsed_board {
+		parsed_board[i] = piece_is_invalid
+	}
+
+	ranks := strings.Split(board, "/")
+	if len(ranks) != 8 {
+		return nil, fmt.Errorf("FEN board needs 8 ranks [%s]", board)
+	}
+
+	kings := map[piece]int{}
+	for r, rank := range ranks {
+		file := 0
+		for _, c := range []byte(rank) {
+			if '1' <= c && c <= '8' {
+				if file+int(c-'0') > 8 {
+					return nil, fmt.Errorf("FEN bad rank %d [%s]", 8-r, rank)
+				}
+				for k := 0; k < int(c-'0'); k++ {
+					parsed_board[a8+r*south+file] = piece_is_empty
+					file++
+				}
+				continue
+			}
+
+			if !strings.ContainsRune("PNBRQKpnbrqk", rune(c)) || file >= 8 {
+				return nil, fmt.Errorf("FEN bad rank %d [%s]", 8-r, rank)
+			}
+
+			p, _ := make_piece(c)
+			if (p == piece_p || p == piece_p|piece_is_lower) && (r == 0 || r == 7) {
+				return nil, fmt.Errorf("FEN pawn on rank %d [%s]", 8-r, rank)
+			}
+			kings[p]++
+			parsed_board[a8+r*south+file] = p
+			file++
+		}
+
+		if file != 8 {
+			return nil, fmt.Errorf("FEN bad rank %d [%s]", 8-r, rank)
+		}
+	}
+
+	if kings[piece_k] != 1 || kings[piece_k|piece_is_lower] != 1 {
+		return nil, fmt.Errorf("FEN needs one king per side [%s]", board)
+	}
+
+	if color != "w" && color != "b" {
+		return nil, fmt.Errorf("FEN bad side to move [%s]", color)
+	}
+
+	if castling != "-" {
+		for i, c := range castling {
+			if !strings.ContainsRune("KQkq", c) || strings.ContainsRune(castling[i+1:], c) {
+				return nil, fmt.Errorf("FEN bad castling rights [%s]", castling)
+			}
+		}
+	}
+	wc := [2]bool{strings.Contains(castling, "Q"), strings.Contains(castling, "K")}
+	bc := [2]bool{strings.Contains(castling, "k"), strings.Contains(castling, "q")}
+
+	// Each right needs its king and rook still at home
+	for _, right := range []struct {
+		has        bool
+		king, rook int
+		p          piece
+	}{
+		{wc[0], a1 + 4*east, a1, 0},
+		{wc[1], a1 + 4*east, h1, 0},
+		{bc[0], a8 + 4*east, h8, piece_is_lower},
+		{bc[1], a8 + 4*east, a8, piece_is_lower},
+	} {
+		if right.has && (parsed_board[right.king] != piece_k|right.p || parsed_board[right.rook] != piece_r|right.p) {
+			return nil, fmt.Errorf("FEN castling rights without king and rook [%s]", castling)
+		}
+	}
+
+	ep := 0
+	if enpas != "-" {
+		square, ok := parseSquare(enpas)
+		if !ok || (color == "w" && enpas[1] != '6') || (color == "b" && enpas[1] != '3') {
+			return nil, fmt.Errorf("FEN bad en passant square [%s]", enpas)
+		}
+		// The pawn that just moved two squares, and the squares it crossed
+		pawn, from, p := square+south, square+north, piece(piece_p|piece_is_lower)
+		if color == "b" {
+			pawn, from, p = square+north, square+south, piece_p
+		}
+		if parsed_board[pawn] != p || parsed_board[square] != piece_is_empty || parsed_board[from] != piece_is_empty {
+			return nil, fmt.Errorf("FEN en passant square without a pawn that just moved [%s]", enpas)
+		}
+		ep = square
+	}
+
+	halfmove, fullmove := 0, 1
+	if len(parts) > 4 {
+		var err error
+		if halfmove, err = strconv.Atoi(parts[4]); err != nil || halfmove < 0 {
+			return nil, fmt.Errorf("FEN bad halfmove clock [%s]", parts[4])
+		}
+	}
+	if len(parts) > 5 {
+		var err error
+		if fullmove, err = strconv.Atoi(parts[5]); err != nil || fullmove < 1 {
+			return nil, fmt.Errorf("FEN bad fullmove number [%s]", parts[5])
+		}
+	}
+
+	mg, eg, phase := 0, 0, 0
+	for i, p := range parsed_board {
+		if p.isupper() {
+			mg += pst[p][i]
+			eg += pst_eg[p][i]
+			phase += phase_weight[p]
+		}
+		if p.islower() {
+			mg -= pst[p.swapcase()][119-i]
+			eg -= pst_eg[p.swapcase()][119-i]
+			phase += phase_weight[p.swapcase()]
+		}
+	}
+
+	pos := Position{
+		board:    parsed_board,
+		score:    taper(mg, eg, phase),
+		mg:       mg,
+		eg:       eg,
+		phase:    phase,
+		wc:       wc,
+		bc:       bc,
+		ep:       ep,
+		kp:       0,
+		halfmove: halfmove,
+		ply:      2 * (fullmove - 1),
+	}
+	pos.hash, pos.rhash, pos.pawn_hash, pos.pawn_rhash = pos.compute_hash()
+
+	if color == "w" {
+		return &pos, nil
+	}
+
+	pos.ply++
+	return pos.rotate(), nil
+}
+
+// NewPosition parses a FEN string, such as FEN_INITIAL.
+func NewPosition(fen string) (*Position, error) {
+	return parseFEN(fen)
+}
+
+// FEN returns the position in Forsyth-Edwards Notation.
+func (self *Position) FEN() string {
+	return self.fen()
+}
+
+func (self *Position) WhiteToMove() bool {
+	return self.white_turn()
+}
+
+func (self *Position) InCheck() bool {
+	return self.in_check()
+}
+
+// PieceAt returns the piece on a square such as "e4", as a FEN letter, or ""
+// when the square is empty.
+func (self *Position) PieceAt(square string) string {
+	sq, ok := parseSquare(square)
+	if !ok {
+		return ""
+	}
+
+	p := self.white_view().board[sq]
+	if p == piece_is_empty {
+		return ""
+	}
+	return p.String()
+}
+
+// Play returns the position after a legal move. The new position remembers
+// the earlier ones, so that the searcher can see repetitions.
+func (self *Position) Play(move Move) (*Position, error) {
+	// Moves are seen from the side to move inside the package
+	m := move
+	if !self.white_turn() {
+		m = move.rotate()
+	}
+	if !self.is_legal(m) {
+		return nil, fmt.Errorf("illegal move [%s] in [%s]", move, self.fen())
+	}
+
+	pos := self.move(m)
+	if pos.halfmove > 0 {
+		pos.history = make([]uint64, len(self.history), len(self.history)+1)
+		copy(pos.history, self.history)
+		pos.history = append(pos.history, self.hash)
+	}
+
+	return pos, nil
+}
+
+// ParseMove reads a move in UCI notation, such as "e2e4" or "e7e8q".
+func ParseMove(str string) (Move, error) {
+	move, ok := parseMove(str)
+	if !ok {
+		return Move{}, fmt.Errorf("bad move [%s]", str)
+	}
+	return move, nil
+}
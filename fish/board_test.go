@@ -0,0 +1,53 @@
+package fish
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFEN(t *testing.T) {
+	good := []string{
+		FEN_INITIAL,
+		"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 3 20",
+		"rnbqkbnr/ppp1pppp/8/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 3",
+		"rnbqkbnr/pppp1ppp/8/8/3Pp3/8/PPP1PPPP/RNBQKBNR b KQkq d3 0 3",
+	}
+	for _, fen := range good {
+		pos, err := parseFEN(fen)
+		if err != nil {
+			t.Errorf("%s: %v", fen, err)
+			continue
+		}
+		if pos.fen() != fen {
+			t.Errorf("%s comes back as %s", fen, pos.fen())
+		}
+	}
+
+	bad := []string{
+		"8/8/8/8/8/8/8/8 w - - 0 1",
+		"4k3/8/8/8/8/8/8/5K1R w K - 0 1",
+		"4k3/8/8/8/8/8/8/Q3K3 w Q - 0 1",
+		"r3k3/8/8/8/8/8/8/4K3 w k - 0 1",
+		"4k3/8/8/8/8/8/8/4K3 w - e6 0 1",
+		"4k3/8/8/4p3/8/8/8/4K3 b - e6 0 1",
+		"4k3/4p3/8/4p3/8/8/8/4K3 w - e6 0 1",
+		"4k3/8/8/8/4P3/8/4P3/4K3 b - e3 0 1",
+	}
+	for _, fen := range bad {
+		if _, err := parseFEN(fen); err == nil {
+			t.Errorf("%s should not parse", fen)
+		}
+	}
+}
+
+func TestPlayError(t *testing.T) {
+	pos, _ := NewPosition(FEN_INITIAL)
+	move, _ := ParseMove("e2e4")
+	pos, _ = pos.Play(move)
+
+	// The error names the move as it was given, not as black sees it
+	_, err := pos.Play(move)
+	if err == nil || !strings.Contains(err.Error(), "[e2e4]") {
+		t.Errorf("error %v", err)
+	}
+}
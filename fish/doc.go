@@ -0,0 +1,17 @@
+// Package fish is a small chess engine after Sunfish: a 120-square mailbox
+// board, piece-square tables and an MTD-bi search.
+//
+//	pos, _ := fish.NewPosition(fish.FEN_INITIAL)
+//	pos, _ = pos.Play(move)
+//	best := fish.NewSearcher().Search(ctx, pos, fish.Limits{Depth: 8}, func(info fish.Info) {})
+//	fmt.Println(best.Move)
+//
+// Moves given to and returned by the package are from white's point of view,
+// as in UCI. Inside, everything is seen from the side to move.
+//
+// Hash size, threads, MultiPV and strategy belong to each Searcher, but the
+// settings of SetSetting and the evaluation of SetEval and LoadEval are
+// shared by the whole package. Change them only while no Searcher is
+// searching; a program running several searchers at once should set them
+// up before the first search and leave them alone.
+package fish
@@ -0,0 +1,304 @@
+package fish
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// piece_value and piece_square are the middlegame evaluation in its readable
+// layout, as in an eval file: the square bonuses go from a8 to h1. The search
+// uses pst, which joins them on the padded 120-square board.
+var piece_value = [6]int{
+	// P
+	100,
+	// N
+	280,
+	// B
+	320,
+	// R
+	479,
+	// Q
+	929,
+	// K
+	60_000,
+}
+
+var piece_square = [6][64]int{
+	// piece_p
+	{0, 0, 0, 0, 0, 0, 0, 0,
+		78, 83, 86, 73, 102, 82, 85, 90,
+		7, 29, 21, 44, 40, 31, 44, 7,
+		-17, 16, -2, 15, 14, 0, 15, -13,
+		-26, 3, 10, 9, 6, 1, 0, -23,
+		-22, 9, 5, -11, -10, -2, 3, -19,
+		-31, 8, -7, -37, -36, -14, 3, -31,
+		0, 0, 0, 0, 0, 0, 0, 0},
+	// piece_n
+	{-66, -53, -75, -75, -10, -55, -58, -70,
+		-3, -6, 100, -36, 4, 62, -4, -14,
+		10, 67, 1, 74, 73, 27, 62, -2,
+		24, 24, 45, 37, 33, 41, 25, 17,
+		-1, 5, 31, 21, 22, 35, 2, 0,
+		-18, 10, 13, 22, 18, 15, 11, -14,
+		-23, -15, 2, 0, 2, 0, -23, -20,
+		-74, -23, -26, -24, -19, -35, -22, -69},
+	// piece_b
+	{-59, -78, -82, -76, -23, -107, -37, -50,
+		-11, 20, 35, -42, -39, 31, 2, -22,
+		-9, 39, -32, 41, 52, -10, 28, -14,
+		25, 17, 20, 34, 26, 25, 15, 10,
+		13, 10, 17, 23, 17, 16, 0, 7,
+		14, 25, 24, 15, 8, 25, 20, 15,
+		19, 20, 11, 6, 7, 6, 20, 16,
+		-7, 2, -15, -12, -14, -15, -10, -10},
+	// piece_r
+	{35, 29, 33, 4, 37, 33, 56, 50,
+		55, 29, 56, 67, 55, 62, 34, 60,
+		19, 35, 28, 33, 45, 27, 25, 15,
+		0, 5, 16, 13, 18, -4, -9, -6,
+		-28, -35, -16, -21, -13, -29, -46, -30,
+		-42, -28, -42, -25, -25, -35, -26, -46,
+		-53, -38, -31, -26, -29, -43, -44, -53,
+		-30, -24, -18, 5, -2, -18, -31, -32},
+	// piece_q
+	{6, 1, -8, -104, 69, 24, 88, 26,
+		14, 32, 60, -10, 20, 76, 57, 24,
+		-2, 43, 32, 60, 72, 63, 43, 2,
+		1, -16, 22, 17, 25, 20, -13, -6,
+		-14, -15, -2, -5, -1, -10, -20, -22,
+		-30, -6, -13, -11, -16, -11, -16, -27,
+		-36, -18, 0, -19, -15, -15, -21, -38,
+		-39, -30, -31, -13, -31, -36, -34, -42},
+	// piece_k
+	{4, 54, 47, -99, -99, 60, 83, -62,
+		-32, 10, 55, 56, 56, 55, 10, 3,
+		-62, 12, -57, 44, -67, 28, 37, -31,
+		-55, 50, 11, -4, -19, 13, 0, -49,
+		-55, -43, -52, -28, -51, -47, -8, -50,
+		-47, -42, -43, -79, -64, -32, -29, -32,
+		-4, 3, -14, -50, -57, -18, 13, 4,
+		17, 30, -3, -14, 6, -1, 40, 18},
+}
+
+// The endgame evaluation, joined into pst_eg. Pawns get more for advancing
+// and the king leaves its shelter for the center; other pieces are valued as
+// in the middlegame.
+var piece_value_eg = piece_value
+
+var piece_square_eg = [6][64]int{
+	// piece_p
+	{0, 0, 0, 0, 0, 0, 0, 0,
+		130, 130, 130, 130, 130, 130, 130, 130,
+		80, 80, 80, 80, 80, 80, 80, 80,
+		50, 50, 50, 50, 50, 50, 50, 50,
+		25, 25, 25, 25, 25, 25, 25, 25,
+		10, 10, 10, 10, 10, 10, 10, 10,
+		0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0},
+	piece_square[piece_n],
+	piece_square[piece_b],
+	piece_square[piece_r],
+	piece_square[piece_q],
+	// piece_k
+	{-50, -40, -30, -20, -20, -30, -40, -50,
+		-30, -20, -10, 0, 0, -10, -20, -30,
+		-30, -10, 20, 30, 30, 20, -10, -30,
+		-30, -10, 30, 40, 40, 30, -10, -30,
+		-30, -10, 30, 40, 40, 30, -10, -30,
+		-30, -10, 20, 30, 30, 20, -10, -30,
+		-30, -30, 0, 0, 0, 0, -30, -30,
+		-50, -30, -30, -30, -30, -30, -30, -50},
+}
+
+var pst, pst_eg piece_table
+
+func init() {
+	pst = join_pst(piece_value, piece_square)
+	pst_eg = join_pst(piece_value_eg, piece_square_eg)
+}
+
+func join_pst(values [6]int, squares [6][64]int) piece_table {
+	result := make(piece_table, len(values))
+	for p := range values {
+		result[p] = make(int_array, 120)
+		for i := 0; i < 64; i++ {
+			result[p][a8+i/8*10+i%8] = values[p] + squares[p][i]
+		}
+	}
+	return result
+}
+
+// The game phase goes from max_phase with all the pieces on the board down
+// to 0 with none, pawns and kings aside. Scores slide from the middlegame
+// tables to the endgame ones with it.
+const max_phase = 24
+
+var phase_weight = [6]int{0, 1, 1, 2, 4, 0}
+
+func taper(mg, eg, phase int) int {
+	phase = min(phase, max_phase)
+	return (mg*phase + eg*(max_phase-phase)) / max_phase
+}
+
+const piece_letters = "PNBRQK"
+
+// EvalParams is the content of an eval file, keyed by piece letter (PNBRQK).
+// Pieces left out keep their built-in values. Square bonuses are lists of 64,
+// from a8 to h1 as white sees the board. The _eg ones are for the endgame.
+type EvalParams struct {
+	Piece   map[string]int   `json:"piece"`
+	PST     map[string][]int `json:"pst"`
+	PieceEG map[string]int   `json:"piece_eg"`
+	PSTEG   map[string][]int `json:"pst_eg"`
+}
+
+// DefaultEval returns the built-in evaluation, ready to be saved as a
+// starting point for an eval file.
+func DefaultEval() EvalParams {
+	params := EvalParams{
+		Piece:   map[string]int{},
+		PST:     map[string][]int{},
+		PieceEG: map[string]int{},
+		PSTEG:   map[string][]int{},
+	}
+	for p, letter := range piece_letters {
+		params.Piece[string(letter)] = piece_value[p]
+		params.PST[string(letter)] = append([]int{}, piece_square[p][:]...)
+		params.PieceEG[string(letter)] = piece_value_eg[p]
+		params.PSTEG[string(letter)] = append([]int{}, piece_square_eg[p][:]...)
+	}
+	return params
+}
+
+// SetEval replaces the evaluation used by new positions. No search may be
+// running, and the transposition table should be cleared afterwards.
+func SetEval(params EvalParams) error {
+	values, squares := piece_value, piece_square
+	values_eg, squares_eg := piece_value_eg, piece_square_eg
+
+	if err := set_values(&values, params.Piece); err != nil {
+		return err
+	}
+	if err := set_squares(&squares, params.PST); err != nil {
+		return err
+	}
+	if err := set_values(&values_eg, params.PieceEG); err != nil {
+		return err
+	}
+	if err := set_squares(&squares_eg, params.PSTEG); err != nil {
+		return err
+	}
+
+	pst = join_pst(values, squares)
+	pst_eg = join_pst(values_eg, squares_eg)
+	return nil
+}
+
+func set_values(values *[6]int, params map[string]int) error {
+	for letter, value := range params {
+		p := strings.Index(piece_letters, letter)
+		if len(letter) != 1 || p < 0 {
+			return fmt.Errorf("unknown piece [%s]", letter)
+		}
+		if p == piece_k && value != piece_value[piece_k] {
+			// The mate scores are derived from it
+			return fmt.Errorf("the king value is fixed at %d", piece_value[piece_k])
+		}
+		values[p] = value
+	}
+	return nil
+}
+
+func set_squares(squares *[6][64]int, params map[string][]int) error {
+	for letter, table := range params {
+		p := strings.Index(piece_letters, letter)
+		if len(letter) != 1 || p < 0 {
+			return fmt.Errorf("unknown piece [%s]", letter)
+		}
+		if len(table) != 64 {
+			return fmt.Errorf("table of piece %s has %d squares, not 64", letter, len(table))
+		}
+		copy(squares[p][:], table)
+	}
+	return nil
+}
+
+// LoadEval reads an eval file in JSON and makes it the evaluation, see
+// SetEval. An empty path restores the built-in one.
+func LoadEval(path string) error {
+	if path == "" {
+		return SetEval(EvalParams{})
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	params := EvalParams{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&params); err != nil {
+		return fmt.Errorf("eval file %s: %w", path, err)
+	}
+
+	return SetEval(params)
+}
+
+// value is how much move changes the score of the position.
+func (self *Position) value(move Move) int {
+	mg, eg, phase := self.deltas(move)
+	return taper(self.mg+mg, self.eg+eg, self.phase+phase) - self.score
+}
+
+// deltas are the changes move makes to the middlegame and endgame scores,
+// and to the phase.
+func (self *Position) deltas(move Move) (mg, eg, phase int) {
+	q := self.board[move.to]
+	if q.islower() {
+		phase -= phase_weight[q.swapcase()]
+	}
+	if self.board[move.from] == piece_p && a8 <= move.to && move.to <= h8 {
+		phase += phase_weight[move.promotion()]
+	}
+
+	return self.table_value(pst, move), self.table_value(pst_eg, move), phase
+}
+
+func (self *Position) table_value(pst piece_table, move Move) int {
+	i, j := move.from, move.to
+	p, q := self.board[i], self.board[j]
+
+	score := pst[p][j] - pst[p][i]
+
+	if q.islower() {
+		score += pst[q.swapcase()][119-j]
+	}
+
+	if abs(j-self.kp) < 2 {
+		score += pst[piece_k][119-j]
+	}
+
+	if p == piece_k && abs(i-j) == 2 {
+		score += pst[piece_r][(i+j)/2]
+		if j < i {
+			score -= pst[piece_r][a1]
+		} else {
+			score -= pst[piece_r][h1]
+		}
+	}
+
+	if p == piece_p {
+		if a8 <= j && j <= h8 {
+			score += pst[move.promotion()][j] - pst[piece_p][j]
+		}
+		if j == self.ep {
+			score += pst[piece_p][119-(j+south)]
+		}
+	}
+
+	return score
+}
@@ -0,0 +1,80 @@
+package fish
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetEval(t *testing.T) {
+	defer SetEval(EvalParams{})
+	builtin := join_pst(piece_value, piece_square)
+
+	if err := SetEval(DefaultEval()); err != nil || !reflect.DeepEqual(pst, builtin) {
+		t.Errorf("the default eval changed the tables: %v", err)
+	}
+
+	pos, _ := NewPosition("4k3/8/8/8/8/8/8/1N2K3 w - - 0 1")
+	before := pos.score
+	if err := SetEval(EvalParams{Piece: map[string]int{"N": 380}, PieceEG: map[string]int{"N": 380}}); err != nil {
+		t.Fatal(err)
+	}
+	pos, _ = NewPosition("4k3/8/8/8/8/8/8/1N2K3 w - - 0 1")
+	if pos.score != before+100 {
+		t.Errorf("score = %d, want %d", pos.score, before+100)
+	}
+
+	bad := []EvalParams{
+		{Piece: map[string]int{"X": 1}},
+		{Piece: map[string]int{"K": 1000}},
+		{PST: map[string][]int{"P": {1, 2, 3}}},
+	}
+	for _, params := range bad {
+		if err := SetEval(params); err == nil {
+			t.Errorf("SetEval(%v) should fail", params)
+		}
+	}
+
+	if err := SetEval(EvalParams{}); err != nil || !reflect.DeepEqual(pst, builtin) {
+		t.Errorf("an empty eval should restore the built-in one")
+	}
+}
+
+func checkScores(t *testing.T, pos *Position, depth int) {
+	if pos.score <= -MATE_LOWER {
+		return
+	}
+	fresh, err := parseFEN(pos.fen())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pos.mg != fresh.mg || pos.eg != fresh.eg || pos.phase != fresh.phase || pos.score != fresh.score {
+		t.Fatalf("%s: incremental score differs from a fresh one", pos.fen())
+	}
+	if depth == 0 {
+		return
+	}
+
+	pos.gen_moves(func(m Move) bool {
+		checkScores(t, pos.move(m), depth-1)
+		return false
+	})
+}
+
+func TestTaperedEval(t *testing.T) {
+	for _, p := range perftPositions {
+		pos, _ := parseFEN(p.fen)
+		checkScores(t, pos, 2)
+	}
+
+	start, _ := parseFEN(FEN_INITIAL)
+	if start.phase != max_phase || start.score != start.mg {
+		t.Errorf("the opening should be all middlegame")
+	}
+
+	// In a pawn ending the king belongs in the center
+	center, _ := parseFEN("6k1/8/8/8/4K3/8/4P3/8 w - - 0 1")
+	corner, _ := parseFEN("6k1/8/8/8/8/8/4P3/6K1 w - - 0 1")
+	if center.score <= corner.score {
+		t.Errorf("king in the center %d, in the corner %d", center.score, corner.score)
+	}
+}
@@ -0,0 +1,31 @@
+package fish_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kargeor/golang-fish/fish"
+)
+
+func ExampleSearcher_Search() {
+	pos, _ := fish.NewPosition("6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1")
+
+	best := fish.NewSearcher().Search(context.Background(), pos, fish.Limits{Depth: 4}, func(info fish.Info) {})
+	moves, _ := best.Mate()
+	fmt.Println(best.Move, moves)
+	// Output: d1d8 1
+}
+
+func ExamplePosition_Play() {
+	pos, _ := fish.NewPosition(fish.FEN_INITIAL)
+	for _, str := range []string{"e2e4", "e7e5", "g1f3"} {
+		move, _ := fish.ParseMove(str)
+		pos, _ = pos.Play(move)
+	}
+
+	fmt.Println(pos.FEN())
+	fmt.Println(len(pos.LegalMoves()))
+	// Output:
+	// rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2
+	// 29
+}
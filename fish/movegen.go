@@ -0,0 +1,130 @@
+package fish
+
+var directions = piece_table{
+	// piece_p
+	{north, north + north, north + west, north + east},
+	// piece_n
+	{north + north + east, east + north + east, east + south + east, south + south + east, south + south + west, west + south + west, west + north + west, north + north + west},
+	// piece_b
+	{north + east, south + east, south + west, north + west},
+	// piece_r
+	{north, east, south, west},
+	// piece_q
+	{north, east, south, west, north + east, south + east, south + west, north + west},
+	// piece_k
+	{north, east, south, west, north + east, south + east, south + west, north + west},
+}
+
+func (self *Position) gen_moves(yield func(m Move) bool) {
+	for i, p := range self.board {
+		if !(p.isupper()) {
+			continue
+		}
+		for _, d := range directions[p] {
+			for j := (i + d); ; j += d {
+				q := self.board[j]
+				if q.is_invalid_space() || q.isupper() {
+					break
+				}
+
+				if p == piece_p {
+					if (d == north || d == north+north) && q != piece_is_empty {
+						break
+					}
+
+					if d == north+north && (i < a1+north || self.board[i+north] != piece_is_empty) {
+						break
+					}
+
+					if (d == north+west || d == north+east) && q == piece_is_empty && j != self.ep && j != self.kp && j != self.kp-1 && j != self.kp+1 {
+						break
+					}
+				}
+
+				if p == piece_p && a8 <= j && j <= h8 {
+					for _, prom := range []piece{piece_q, piece_n, piece_r, piece_b} {
+						if yield(Move{i, j, int(prom)}) {
+							return
+						}
+					}
+				} else if yield(Move{from: i, to: j}) {
+					return
+				}
+
+				if q.islower() || p == piece_p || p == piece_n || p == piece_k {
+					break
+				}
+
+				if i == a1 && self.board[j+east] == piece_k && self.wc[0] {
+					if yield(Move{from: j + east, to: j + west}) {
+						return
+					}
+				}
+
+				if i == h1 && self.board[j+west] == piece_k && self.wc[1] {
+					if yield(Move{from: j + west, to: j + east}) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+func (self *Position) is_dead() bool {
+	result := false
+	self.gen_moves(func(m Move) bool {
+		// Only taking the king scores this much, in either table
+		if self.table_value(pst, m) >= MATE_LOWER {
+			result = true
+			return true
+		}
+		return false
+	})
+
+	return result
+}
+
+// in_check tells whether the opponent could capture our king if we passed.
+func (self *Position) in_check() bool {
+	return self.nullmove().is_dead()
+}
+
+// legal_moves filters gen_moves down to the moves that don't leave our king
+// capturable. Castling out of or through check is caught by the kp squares.
+func (self *Position) legal_moves() []Move {
+	moves := []Move{}
+	self.gen_moves(func(m Move) bool {
+		// Pawn "captures" of a king that just castled are not real moves
+		if self.board[m.from] == piece_p && (m.to-m.from)%south != 0 && self.board[m.to] == piece_is_empty && m.to != self.ep {
+			return false
+		}
+		if !self.move(m).is_dead() {
+			moves = append(moves, m)
+		}
+		return false
+	})
+
+	return moves
+}
+
+func (self *Position) is_legal(move Move) bool {
+	for _, m := range self.legal_moves() {
+		if m == move {
+			return true
+		}
+	}
+	return false
+}
+
+// LegalMoves returns the legal moves from white's point of view, ready to
+// print or to Play.
+func (self *Position) LegalMoves() []Move {
+	moves := self.legal_moves()
+	if !self.white_turn() {
+		for i := range moves {
+			moves[i] = moves[i].rotate()
+		}
+	}
+	return moves
+}
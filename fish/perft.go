@@ -0,0 +1,40 @@
+package fish
+
+// Perft counts the leaf nodes of the legal move tree, to check the move
+// generator against known numbers.
+func (self *Position) Perft(depth int) int {
+	if depth <= 0 {
+		return 1
+	}
+
+	moves := self.legal_moves()
+	if depth == 1 {
+		return len(moves)
+	}
+
+	nodes := 0
+	for _, m := range moves {
+		nodes += self.move(m).Perft(depth - 1)
+	}
+
+	return nodes
+}
+
+// Divide yields the perft count below each root move, from white's point of
+// view, and returns the total.
+func (self *Position) Divide(depth int, yield func(m Move, nodes int)) int {
+	white_turn := self.white_turn()
+	total := 0
+
+	for _, m := range self.legal_moves() {
+		nodes := self.move(m).Perft(depth - 1)
+		total += nodes
+
+		if !white_turn {
+			m = m.rotate()
+		}
+		yield(m, nodes)
+	}
+
+	return total
+}
@@ -0,0 +1,36 @@
+package fish
+
+import "testing"
+
+var perftPositions = []struct {
+	name  string
+	fen   string
+	nodes []int
+}{
+	{"start", FEN_INITIAL, []int{20, 400, 8902, 197281}},
+	{"kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", []int{48, 2039, 97862}},
+	{"en passant", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", []int{14, 191, 2812, 43238}},
+	{"promotions", "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", []int{6, 264, 9467}},
+	{"promotions black", "r2q1rk1/pP1p2pp/Q4n2/bbp1p3/Np6/1B3NBn/pPPP1PPP/R3K2R b KQ - 0 1", []int{6, 264, 9467}},
+	{"castling", "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8", []int{44, 1486, 62379}},
+	{"middlegame", "r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10", []int{46, 2079, 89890}},
+}
+
+func TestPerft(t *testing.T) {
+	for _, p := range perftPositions {
+		pos, err := parseFEN(p.fen)
+		if err != nil {
+			t.Fatalf("%s: %s", p.name, err)
+		}
+
+		for i, expected := range p.nodes {
+			depth := i + 1
+			if testing.Short() && expected > 10000 {
+				break
+			}
+			if nodes := pos.Perft(depth); nodes != expected {
+				t.Errorf("%s: perft(%d) = %d, want %d", p.name, depth, nodes, expected)
+			}
+		}
+	}
+}
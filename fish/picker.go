@@ -0,0 +1,197 @@
+package fish
+
+// Stages of pick_moves, in the order they come.
+const (
+	stage_hash = iota
+	stage_capture
+	stage_killer
+	stage_quiet
+	stage_bad_capture
+)
+
+// With setting_see, quiescence search skips the captures that lose material
+// and the main search tries them last.
+var setting_see = true
+
+// is_quiet tells whether move neither captures nor promotes. Moves onto the
+// squares a castling king crossed capture it.
+func (self *Position) is_quiet(move Move) bool {
+	i, j := move.from, move.to
+	if self.board[j] != piece_is_empty || move.prom != 0 {
+		return false
+	}
+	if self.board[i] == piece_p && j == self.ep {
+		return false
+	}
+	return self.kp == 0 || abs(j-self.kp) >= 2
+}
+
+// mvv_lva orders captures by the most valuable victim, then the least
+// valuable attacker. Promotions count the piece they make.
+func (self *Position) mvv_lva(move Move) int {
+	i, j := move.from, move.to
+	p, q := self.board[i], self.board[j]
+
+	victim := 0
+	switch {
+	case q.islower():
+		victim = piece_value[q.swapcase()]
+	case self.kp != 0 && abs(j-self.kp) < 2:
+		victim = piece_value[piece_k]
+	case p == piece_p && j == self.ep:
+		victim = piece_value[piece_p]
+	}
+	if move.prom != 0 {
+		victim += piece_value[move.prom] - piece_value[piece_p]
+	}
+
+	return 16*victim - piece_value[p]
+}
+
+// loses_material tells whether the exchange started by move is lost. Taking
+// a piece at least as valuable as our own can't be, unless the king takes,
+// and neither can taking a king that castled through the square.
+func (self *Position) loses_material(move Move) bool {
+	if self.kp != 0 && abs(move.to-self.kp) < 2 {
+		return false
+	}
+	p, q := self.board[move.from], self.board[move.to]
+	if q.islower() && p != piece_k && piece_value[q.swapcase()] >= piece_value[p] {
+		return false
+	}
+	return self.see(move) < 0
+}
+
+// max_history bounds the history scores, see good_quiet.
+const max_history = 1 << 20
+
+// by_value orders moves by score, and those of the same score by value.
+func by_value(pos *Position, score int, move Move) score_move {
+	return score_move{score<<11 + max(-1024, min(pos.value(move), 1023)), move}
+}
+
+// select_move swaps the best of moves to the front.
+func select_move(moves []score_move) {
+	best := 0
+	for j := 1; j < len(moves); j++ {
+		if moves[j].score > moves[best].score {
+			best = j
+		}
+	}
+	moves[0], moves[best] = moves[best], moves[0]
+}
+
+// pick_moves yields the moves of pos to search, with their stage: the hash
+// move, captures by MVV-LVA, the killers of this ply and the other quiet
+// moves by history, then value, and last the captures that lose material.
+// Quiescence search (depth 0) only gets the hash move and the moves worth
+// setting_qs_limit that don't lose material, by value.
+//
+// Each stage does its work only when the ones before it didn't cut off:
+// the moves are generated after the hash move, and each one is scored,
+// checked for SEE and picked when its stage comes.
+func (self *worker) pick_moves(pos *Position, depth int, ply int, hash Move, yield func(move Move, stage int) bool) {
+	if hash != (Move{}) && (depth > 0 || pos.value(hash) >= setting_qs_limit) {
+		if yield(hash, stage_hash) {
+			return
+		}
+	}
+
+	captures := make([]score_move, 0, 16)
+	quiets := make([]Move, 0, 48)
+	pos.gen_moves(func(m Move) bool {
+		switch {
+		case m == hash:
+		case depth == 0:
+			if value := pos.value(m); value >= setting_qs_limit {
+				captures = append(captures, score_move{value, m})
+			}
+		case pos.is_quiet(m):
+			quiets = append(quiets, m)
+		default:
+			captures = append(captures, by_value(pos, pos.mvv_lva(m), m))
+		}
+		return false
+	})
+
+	bad_captures := []Move{}
+	for ; len(captures) > 0; captures = captures[1:] {
+		select_move(captures)
+		m := captures[0].move
+		if setting_see && pos.loses_material(m) {
+			if depth > 0 {
+				bad_captures = append(bad_captures, m)
+			}
+			continue
+		}
+		if yield(m, stage_capture) {
+			return
+		}
+	}
+	if depth == 0 {
+		return
+	}
+
+	if ply < len(self.killers) {
+		for _, killer := range self.killers[ply] {
+			for i := range quiets {
+				if quiets[i] == killer {
+					quiets[i] = quiets[len(quiets)-1]
+					quiets = quiets[:len(quiets)-1]
+					if yield(killer, stage_killer) {
+						return
+					}
+					break
+				}
+			}
+		}
+	}
+
+	scored := make([]score_move, len(quiets))
+	for i, m := range quiets {
+		scored[i] = by_value(pos, self.quiet_history[pos.board[m.from]][m.to], m)
+	}
+	for ; len(scored) > 0; scored = scored[1:] {
+		select_move(scored)
+		if yield(scored[0].move, stage_quiet) {
+			return
+		}
+	}
+
+	for _, m := range bad_captures {
+		if yield(m, stage_bad_capture) {
+			return
+		}
+	}
+}
+
+// good_quiet remembers a quiet move that failed high, as a killer of its
+// ply and in the history of its piece and square.
+func (self *worker) good_quiet(pos *Position, move Move, depth int, ply int) {
+	if move == (Move{}) || !pos.is_quiet(move) {
+		return
+	}
+
+	for len(self.killers) <= ply {
+		self.killers = append(self.killers, [2]Move{})
+	}
+	if self.killers[ply][0] != move {
+		self.killers[ply][1] = self.killers[ply][0]
+		self.killers[ply][0] = move
+	}
+
+	h := &self.quiet_history[pos.board[move.from]][move.to]
+	*h += depth * depth
+	if *h > max_history {
+		self.age_history()
+	}
+}
+
+// age_history halves the history scores, so that recent cutoffs count more.
+func (self *worker) age_history() {
+	for p := range self.quiet_history {
+		for sq := range self.quiet_history[p] {
+			self.quiet_history[p][sq] /= 2
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package fish
+
+import "testing"
+
+func TestPickMoves(t *testing.T) {
+	pos, _ := NewPosition("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	w := &worker{}
+	hash := Move{}
+	for _, m := range pos.legal_moves() {
+		if pos.is_quiet(m) {
+			hash = m
+			break
+		}
+	}
+	killer := Move{}
+	for _, m := range pos.legal_moves() {
+		if pos.is_quiet(m) && m != hash {
+			killer = m
+		}
+	}
+	w.good_quiet(pos, killer, 4, 0)
+
+	// History beats value: the worst quiet move comes first with some
+	worst := Move{}
+	for _, m := range pos.legal_moves() {
+		if pos.is_quiet(m) && m != hash && m != killer && (worst == Move{} || pos.value(m) < pos.value(worst)) {
+			worst = m
+		}
+	}
+	w.quiet_history[pos.board[worst.from]][worst.to] = 1
+
+	seen := map[Move]bool{}
+	stages := []int{}
+	last_capture := 1 << 30
+	first_quiet := Move{}
+	w.pick_moves(pos, 3, 0, hash, func(m Move, stage int) bool {
+		if stage == stage_capture {
+			if pos.mvv_lva(m) > last_capture {
+				t.Errorf("capture %v out of order", m)
+			}
+			last_capture = pos.mvv_lva(m)
+		}
+		if seen[m] {
+			t.Errorf("%v picked twice", m)
+		}
+		seen[m] = true
+		if len(stages) == 0 || stages[len(stages)-1] != stage {
+			stages = append(stages, stage)
+		}
+		if stage == stage_killer && m != killer {
+			t.Errorf("killer %v, want %v", m, killer)
+		}
+		if stage == stage_quiet && first_quiet == (Move{}) {
+			first_quiet = m
+		}
+		return false
+	})
+
+	if first_quiet != worst {
+		t.Errorf("first quiet move %v, want %v from the history", first_quiet, worst)
+	}
+
+	count := 0
+	pos.gen_moves(func(m Move) bool {
+		count++
+		return false
+	})
+	if len(seen) != count {
+		t.Errorf("picked %d moves of %d", len(seen), count)
+	}
+	want := []int{stage_hash, stage_capture, stage_killer, stage_quiet, stage_bad_capture}
+	if len(stages) != len(want) {
+		t.Fatalf("stages = %v, want %v", stages, want)
+	}
+	for i := range want {
+		if stages[i] != want[i] {
+			t.Errorf("stages = %v, want %v", stages, want)
+		}
+	}
+}
+
+// Castling through check is refuted by taking the king on the square it
+// crossed, even when the rook defends that square.
+func TestCastlingThroughCheckIsCaptured(t *testing.T) {
+	pos, _ := parseFEN("4k3/8/8/8/1b6/8/8/4K2R w K - 0 1")
+	child := pos.move(Move{95, 97, 0})
+	if !child.is_dead() {
+		t.Fatalf("e1g1 should leave the king capturable")
+	}
+
+	w := &worker{searcher: NewSearcher()}
+	w.tt = w.searcher.tt
+	if score := w.bound(child, 0, 0, false); score < MATE_LOWER {
+		t.Errorf("quiescence score %d, want the king capture", score)
+	}
+}
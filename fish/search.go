@@ -0,0 +1,888 @@
+package fish
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Mate scores are MATE_UPPER minus the number of plies until a king is
+// captured, so a side that is checkmated now scores -(MATE_UPPER - 2).
+const MATE_LOWER = 50710
+const MATE_UPPER = 69290
+
+const DEFAULT_HASH_MB = 64
+
+var setting_qs_limit = 219
+var setting_eval_roughness = 13
+var setting_max_depth = 50
+var setting_move_overhead = 250
+
+// Selectivity of the default strategy: checks are extended by one ply, and
+// quiet moves after the first setting_lmr_min_moves are reduced by one.
+var setting_check_extensions = true
+var setting_lmr = true
+var setting_lmr_min_depth = 3
+var setting_lmr_min_moves = 3
+
+type bounds struct {
+	lower, upper int
+}
+
+// Searcher owns a transposition table and runs one search at a time, with
+// as many workers as threads (Lazy SMP). The workers share the table and
+// nothing else; the main one reports, the helpers fill the table for it.
+type Searcher struct {
+	tt      *transposition_table
+	threads int
+	workers []*worker
+
+	// stop may be set from another goroutine to abort a running search.
+	// The main worker always completes its first iteration so there is a
+	// move to play.
+	stop atomic.Bool
+
+	// Hard limits, checked inside bound(). deadline is in unix nanoseconds
+	// and may be moved from another goroutine (ponderhit); 0 means none.
+	deadline  atomic.Int64
+	max_nodes int
+
+	// searchmoves restricts the root to these moves, when not empty.
+	searchmoves []Move
+
+	// multi_pv is the number of lines the main worker searches, at most
+	// max_pv.
+	multi_pv, max_pv int
+
+	strategy strategy
+}
+
+// worker is the state of one search thread; workers[0] is the main one.
+type worker struct {
+	searcher *Searcher
+	tt       *transposition_table
+	id       int
+
+	// nodes is read by the other workers, for the total.
+	nodes           atomic.Int64
+	completed_depth int
+
+	// history holds the hashes of the game before the root, followed by
+	// the positions on the current search path, from root_len on.
+	history  []uint64
+	root_len int
+
+	// Move ordering, see pick_moves
+	killers       [][2]Move
+	quiet_history [6][120]int
+
+	// excluded are the root moves of the lines already searched at this
+	// depth, and root_move is the best move of the current line.
+	excluded  []Move
+	root_move Move
+
+	// gamma is where the last MTD-bi loop ended.
+	gamma int
+
+	// pawns caches the pawn structure scores, see terms.go
+	pawns []pawn_entry
+}
+
+// score_move is a scored child of bound(); move is empty for the null move
+// and for standing pat.
+type score_move struct {
+	score int
+	move  Move
+}
+
+func NewSearcher() *Searcher {
+	searcher := &Searcher{
+		tt:       new_transposition_table(DEFAULT_HASH_MB),
+		threads:  1,
+		max_pv:   1,
+		strategy: strategy_default{},
+	}
+
+	return searcher
+}
+
+// SetHashSize reallocates the transposition table with mb megabytes,
+// dropping its contents.
+func (self *Searcher) SetHashSize(mb int) {
+	self.tt = new_transposition_table(mb)
+}
+
+// SetThreads sets the number of workers of the next searches.
+func (self *Searcher) SetThreads(threads int) {
+	self.threads = max(threads, 1)
+}
+
+// SetMultiPV sets the number of lines the next searches report, the best
+// ones of different moves.
+func (self *Searcher) SetMultiPV(lines int) {
+	self.max_pv = max(lines, 1)
+}
+
+// SetStrategy picks the search of the next searches, one of STRATEGIES.
+func (self *Searcher) SetStrategy(name string) error {
+	strategy, err := find_strategy(name)
+	if err != nil {
+		return err
+	}
+
+	// Scores of one strategy mean little to another
+	self.strategy = strategy
+	self.tt.clear()
+	return nil
+}
+
+// total_nodes sums the nodes of all the workers.
+func (self *Searcher) total_nodes() int {
+	nodes := 0
+	for _, w := range self.workers {
+		nodes += int(w.nodes.Load())
+	}
+	return nodes
+}
+
+// set_history sets the positions played before the next search root, oldest
+// first.
+func (self *worker) set_history(history []uint64) {
+	self.history = make([]uint64, len(history), len(history)+64)
+	copy(self.history, history)
+	self.root_len = len(history)
+}
+
+// is_repetition looks for pos among the earlier positions with the same side
+// to move, as far back as the last capture or pawn move.
+func (self *worker) is_repetition(pos *Position) bool {
+	n := len(self.history)
+	for i := n - 2; i >= 0 && i >= n-pos.halfmove; i -= 2 {
+		if self.history[i] == pos.hash {
+			return true
+		}
+	}
+	return false
+}
+
+// Clear empties the transposition table, for a new game.
+func (self *Searcher) Clear() {
+	self.tt.clear()
+}
+
+// mate_decay moves a child's mate score one ply further away.
+func mate_decay(score int) int {
+	if score >= MATE_LOWER {
+		return score - 1
+	}
+	if score <= -MATE_LOWER {
+		return score + 1
+	}
+	return score
+}
+
+// mate_undecay maps a window to the children's scores: for any child score s,
+// mate_decay(s) >= gamma exactly when s >= mate_undecay(gamma).
+func mate_undecay(gamma int) int {
+	if gamma >= MATE_LOWER {
+		return gamma + 1
+	}
+	if gamma <= -MATE_LOWER+1 {
+		return gamma - 1
+	}
+	return gamma
+}
+
+// mate_moves converts a mate score into moves to mate, negative when the side
+// to move is getting mated. ok is false for ordinary scores.
+func mate_moves(score int) (moves int, ok bool) {
+	if abs(score) < MATE_LOWER {
+		return 0, false
+	}
+
+	plies := MATE_UPPER - abs(score)
+	if score > 0 {
+		return plies / 2, true
+	}
+	return -(plies - 1) / 2, true
+}
+
+// is_mate_window tells the MTD-bi loop to narrow mate scores all the way, so
+// that the reported distance to mate is exact.
+func is_mate_window(lower, upper int) bool {
+	return lower <= -MATE_LOWER || upper >= MATE_LOWER
+}
+
+func (self *worker) root_allows(move Move) bool {
+	for _, m := range self.excluded {
+		if m == move {
+			return false
+		}
+	}
+
+	searchmoves := self.searcher.searchmoves
+	if len(searchmoves) == 0 {
+		return true
+	}
+	for _, m := range searchmoves {
+		if m == move {
+			return true
+		}
+	}
+	return false
+}
+
+// forget_root drops the table entries of the root position, which are only
+// valid for the set of root moves they were searched with.
+func (self *Searcher) forget_root(pos *Position) {
+	self.tt.forget(pos.hash)
+}
+
+func (self *worker) aborted() bool {
+	return self.searcher.stop.Load() && (self.id > 0 || self.completed_depth > 0)
+}
+
+// check_limits is given the nodes of this worker so far.
+func (self *worker) check_limits(nodes int) {
+	s := self.searcher
+
+	if s.max_nodes > 0 {
+		total := nodes
+		if len(s.workers) > 1 {
+			// Summing every worker at every node would be too slow
+			total = 0
+			if nodes&1023 == 0 {
+				total = s.total_nodes()
+			}
+		}
+		if total >= s.max_nodes {
+			s.stop.Store(true)
+		}
+	}
+
+	if nodes&1023 == 0 {
+		deadline := s.deadline.Load()
+		if deadline != 0 && time.Now().UnixNano() >= deadline {
+			s.stop.Store(true)
+		}
+	}
+}
+
+// enter does what every strategy does before looking at the moves of pos:
+// it checks the limits, the king, draws and the table, where the node is
+// kept under flag. done is true when score is already the result.
+func (self *worker) enter(pos *Position, gamma int, depth int, root bool, flag bool) (entry bounds, use_tt bool, score int, done bool) {
+	self.check_limits(int(self.nodes.Add(1)))
+	if self.aborted() {
+		return entry, false, 0, true
+	}
+
+	if pos.score <= -MATE_LOWER {
+		return entry, false, -MATE_UPPER, true
+	}
+
+	// Draws depend on how we got here, so they are checked before the
+	// table and never stored in it.
+	if !root && self.is_repetition(pos) {
+		return entry, false, 0, true
+	}
+	if !root && pos.halfmove >= 100 {
+		// A mate on the hundredth ply still counts
+		score, _ = pos.stuck_score()
+		return entry, false, score, true
+	}
+
+	// The table can't tell apart the lines of a MultiPV search, so only
+	// the first one uses it at the root.
+	use_tt = !root || len(self.excluded) == 0
+
+	entry_found := false
+	if use_tt {
+		entry, entry_found = self.tt.probe_score(pos.hash, depth, flag)
+	}
+	if !entry_found {
+		entry = bounds{-MATE_UPPER, MATE_UPPER}
+	}
+
+	if entry.lower >= gamma {
+		if !root {
+			return entry, use_tt, entry.lower, true
+		}
+		if _, found := self.tt.probe_move(pos.hash); found {
+			return entry, use_tt, entry.lower, true
+		}
+	}
+
+	if entry.upper < gamma {
+		return entry, use_tt, entry.upper, true
+	}
+
+	return entry, use_tt, 0, false
+}
+
+// cutoff records the move that made a node fail high.
+func (self *worker) cutoff(pos *Position, depth int, root bool, flag bool, use_tt bool, move Move) {
+	if root {
+		self.root_move = move
+	}
+	if use_tt {
+		self.tt.store_move(pos.hash, depth, flag, move)
+	}
+}
+
+// leave stores the result of a node in the table.
+func (self *worker) leave(pos *Position, gamma int, depth int, flag bool, use_tt bool, entry bounds, best int) {
+	if use_tt && best >= gamma {
+		self.tt.store_score(pos.hash, depth, flag, bounds{best, entry.upper})
+	}
+
+	if use_tt && best < gamma {
+		self.tt.store_score(pos.hash, depth, flag, bounds{entry.lower, best})
+	}
+}
+
+func (self *worker) bound(pos *Position, gamma int, depth int, root bool) int {
+	depth = max(depth, 0)
+
+	entry, use_tt, score, done := self.enter(pos, gamma, depth, root, root)
+	if done {
+		return score
+	}
+
+	n := len(self.history)
+	self.history = append(self.history, pos.hash)
+	defer func() {
+		self.history = self.history[:n]
+	}()
+
+	// Scores of the children, seen from this node
+	child_gamma := 1 - mate_undecay(gamma)
+	child := func(child_pos *Position, child_depth int) int {
+		return mate_decay(-self.bound(child_pos, child_gamma, child_depth, false))
+	}
+
+	// move_child searches a move one ply deeper when it gives check. A
+	// reduced move is searched one ply shallower first, and again at full
+	// depth only if that fails high.
+	move_child := func(move Move, reduce bool) int {
+		child_pos := pos.move(move)
+		child_depth := depth - 1
+		if setting_check_extensions && depth > 0 && child_pos.in_check() {
+			child_depth = depth
+			reduce = false
+		}
+
+		if reduce {
+			if score := child(child_pos, child_depth-1); score < gamma {
+				return score
+			}
+		}
+		return child(child_pos, child_depth)
+	}
+
+	// Late quiet moves are reduced, unless we are escaping a check
+	can_reduce := setting_lmr && !root && depth >= setting_lmr_min_depth && !pos.in_check()
+	ply := n - self.root_len
+
+	moves := func(yield func(sm score_move) bool) {
+		if depth > 0 && !root {
+			if pos.board.contains(piece_r) ||
+				pos.board.contains(piece_b) ||
+				pos.board.contains(piece_n) ||
+				pos.board.contains(piece_q) {
+				if yield(score_move{
+					score: child(pos.nullmove(), depth-3),
+				}) {
+					return
+				}
+			}
+		}
+
+		if depth == 0 {
+			if yield(score_move{
+				score: self.evaluate(pos),
+			}) {
+				return
+			}
+		}
+
+		killer, _ := self.tt.probe_move(pos.hash)
+		if !use_tt {
+			killer = self.root_move
+		}
+		if root && !self.root_allows(killer) {
+			killer = Move{}
+		}
+
+		searched := 0
+		self.pick_moves(pos, depth, ply, killer, func(move Move, stage int) bool {
+			if root && !self.root_allows(move) {
+				return false
+			}
+
+			reduce := can_reduce && stage == stage_quiet && searched >= setting_lmr_min_moves
+			searched++
+			return yield(score_move{
+				move:  move,
+				score: move_child(move, reduce),
+			})
+		})
+	}
+
+	best := -MATE_UPPER
+	moves(func(sm score_move) bool {
+		// Scores from an aborted subtree are meaningless, don't store them.
+		if self.aborted() {
+			return true
+		}
+
+		best = max(best, sm.score)
+		if best >= gamma {
+			self.cutoff(pos, depth, root, root, use_tt, sm.move)
+			self.good_quiet(pos, sm.move, depth, ply)
+
+			return true
+		}
+
+		return false
+	})
+
+	if self.aborted() {
+		return best
+	}
+
+	if best < gamma && best < 0 && depth > 0 {
+		if score, stuck := pos.stuck_score(); stuck {
+			best = score
+		}
+	}
+
+	self.leave(pos, gamma, depth, root, use_tt, entry, best)
+
+	return best
+}
+
+// stuck_score scores a position where every move lets the king be captured:
+// mated, or stalemated. stuck is false when some move is legal.
+func (self *Position) stuck_score() (score int, stuck bool) {
+	all_is_dead := true
+	self.gen_moves(func(m Move) bool {
+		if !self.move(m).is_dead() {
+			all_is_dead = false
+			return true
+		}
+		return false
+	})
+	if !all_is_dead {
+		return 0, false
+	}
+
+	if self.in_check() {
+		// Same score as when every move lets the king be captured
+		return -(MATE_UPPER - 2), true
+	}
+	return 0, true
+}
+
+const (
+	BOUND_EXACT = iota
+	BOUND_LOWER // fail high: the score is at least this
+	BOUND_UPPER // fail low: the score is at most this
+)
+
+type search_result struct {
+	depth   int
+	multipv int // 1 for the best line
+	move    Move
+	score   int
+	nodes   int
+	bound   int
+	pv      []Move // only for BOUND_EXACT
+}
+
+// pv starts with move and follows the hash moves from there, at most max_len
+// of them. Each move is seen from the side that plays it, like everything
+// else in the search.
+func (self *worker) pv(pos *Position, move Move, max_len int) []Move {
+	pv := []Move{}
+	seen := map[uint64]bool{}
+
+	for found := move != (Move{}); found && len(pv) < max_len; move, found = self.tt.probe_move(pos.hash) {
+		if seen[pos.hash] {
+			break
+		}
+		seen[pos.hash] = true
+
+		next := pos.move(move)
+		if next.score <= -MATE_LOWER || next.is_dead() {
+			// Capturing the king, or leaving it to be captured, is not
+			// part of the game
+			break
+		}
+
+		pv = append(pv, move)
+		pos = next
+	}
+
+	return pv
+}
+
+// line_move is the best root move of the line being searched.
+func (self *worker) line_move(pos *Position) Move {
+	if len(self.excluded) > 0 {
+		return self.root_move
+	}
+	move, _ := self.tt.probe_move(pos.hash)
+	return move
+}
+
+// search deepens iteratively until yield returns true or the search is
+// stopped. Half of the helpers start one ply deeper than the main worker, so
+// that they are not all busy with the same depth. Only the main worker
+// searches more than one line.
+func (self *worker) search(pos *Position, yield func(r search_result) bool) {
+	strategy := self.searcher.strategy
+	lines := 1
+	if self.id == 0 {
+		lines = self.searcher.multi_pv
+	}
+
+	for depth := 1 + self.id%2; depth < 1000; depth++ {
+		self.excluded = self.excluded[:0]
+
+		for multipv := 1; multipv <= lines; multipv++ {
+			self.root_move = Move{}
+
+			lower, upper, gamma := strategy.window(self.gamma)
+			for !strategy.narrow(lower, upper) {
+				score := strategy.bound(self, pos, gamma, depth, true)
+				if self.aborted() {
+					return
+				}
+
+				result := search_result{
+					depth:   depth,
+					multipv: multipv,
+					move:    self.line_move(pos),
+					score:   score,
+					nodes:   self.searcher.total_nodes(),
+				}
+				if score >= gamma {
+					lower = score
+					result.bound = BOUND_LOWER
+				} else {
+					upper = score
+					result.bound = BOUND_UPPER
+				}
+
+				if yield(result) {
+					return
+				}
+
+				gamma = (lower + upper + 1) / 2
+			}
+			self.gamma = gamma
+
+			score := strategy.bound(self, pos, lower, depth, true)
+
+			if self.aborted() {
+				return
+			}
+
+			if multipv == 1 {
+				self.completed_depth = depth
+				if entry, found := self.tt.probe_score(pos.hash, depth, true); found {
+					score = entry.lower
+				}
+			}
+			move := self.line_move(pos)
+			if yield(search_result{
+				depth:   depth,
+				multipv: multipv,
+				move:    move,
+				score:   score,
+				nodes:   self.searcher.total_nodes(),
+				bound:   BOUND_EXACT,
+				pv:      self.pv(pos, move, depth),
+			}) {
+				return
+			}
+
+			self.excluded = append(self.excluded, move)
+		}
+	}
+}
+
+// FormatMoves writes moves in UCI notation, separated by spaces.
+func FormatMoves(moves []Move) string {
+	strs := make([]string, len(moves))
+	for i, m := range moves {
+		strs[i] = m.String()
+	}
+
+	return strings.Join(strs, " ")
+}
+
+// Limits tell Search when to stop. With none of them set it assumes 60
+// seconds for 10 moves.
+type Limits struct {
+	WhiteTime, BlackTime time.Duration
+	WhiteInc, BlackInc   time.Duration
+	MovesToGo            int
+	MoveTime             time.Duration
+	Depth, Nodes, Mate   int
+
+	// Clock tells that the clocks were given, even if they are at zero.
+	// A clock that is set but has no time left only gets the increment.
+	Clock bool
+
+	// Infinite and Ponder searches only return once ctx is done. A Ponder
+	// search goes on the clock when PonderHit is closed instead.
+	Infinite, Ponder bool
+	PonderHit        <-chan struct{}
+
+	// SearchMoves restricts the search to these moves, when not empty.
+	SearchMoves []Move
+}
+
+// time_manager returns nil when only depth, nodes, mate or the caller
+// (infinite) end the search.
+func (self *Limits) time_manager(white_turn bool) *time_manager {
+	if self.MoveTime > 0 {
+		return new_time_manager(0, 0, 0, int(self.MoveTime.Milliseconds()))
+	}
+
+	time_left, inc := self.BlackTime, self.BlackInc
+	if white_turn {
+		time_left, inc = self.WhiteTime, self.WhiteInc
+	}
+
+	if self.Clock || self.WhiteTime != 0 || self.BlackTime != 0 {
+		return new_time_manager(int(time_left.Milliseconds()), int(inc.Milliseconds()), self.MovesToGo, 0)
+	}
+
+	if self.Depth > 0 || self.Nodes > 0 || self.Mate > 0 || self.Infinite {
+		return nil
+	}
+
+	return new_time_manager(60000, 0, 10, 0)
+}
+
+// Info reports one step of a search. Moves are from white's point of view,
+// like the ones of Position.LegalMoves.
+type Info struct {
+	Depth    int
+	MultiPV  int // 1 for the best line, see SetMultiPV
+	Move     Move
+	Score    int // for the side to move, see Mate
+	Bound    int // BOUND_EXACT once the depth is done
+	Nodes    int
+	Time     time.Duration
+	HashFull int    // permille
+	PV       []Move // only for BOUND_EXACT
+}
+
+// Mate converts the score into moves to mate, negative when the side to
+// move is getting mated. ok is false for ordinary scores.
+func (self Info) Mate() (moves int, ok bool) {
+	return mate_moves(self.Score)
+}
+
+// Search thinks about pos until one of the limits is reached or ctx is done,
+// calling info along the way. It returns the last completed depth, whose
+// Move is empty when there is no legal move.
+func (self *Searcher) Search(ctx context.Context, pos *Position, limits Limits, info func(Info)) Info {
+	white_turn := pos.white_turn()
+	tm := limits.time_manager(white_turn)
+
+	// waiting holds the result of infinite and ponder searches. start is
+	// moved on ponderhit, when the clock starts for real.
+	var waiting atomic.Bool
+	var start atomic.Int64
+	waiting.Store(limits.Infinite || limits.Ponder)
+	start.Store(time.Now().UnixNano())
+
+	elapsed := func() time.Duration {
+		return time.Duration(time.Now().UnixNano() - start.Load())
+	}
+	set_deadline := func() {
+		if tm != nil {
+			self.deadline.Store(start.Load() + tm.hard*int64(time.Millisecond))
+		}
+	}
+
+	self.stop.Store(false)
+	self.max_nodes = limits.Nodes
+	self.deadline.Store(0)
+	if !waiting.Load() {
+		set_deadline()
+	}
+	self.searchmoves = nil
+	for _, m := range limits.SearchMoves {
+		if !white_turn {
+			m = m.rotate()
+		}
+		self.searchmoves = append(self.searchmoves, m)
+	}
+	if len(self.workers) != self.threads {
+		self.workers = make([]*worker, self.threads)
+		for i := range self.workers {
+			self.workers[i] = &worker{searcher: self, id: i}
+		}
+	}
+	for _, w := range self.workers {
+		w.tt = self.tt
+		w.nodes.Store(0)
+		w.completed_depth = 0
+		w.excluded = nil
+		w.gamma = 0
+		w.killers = w.killers[:0]
+		w.age_history()
+		w.set_history(pos.history)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ponderhit := limits.PonderHit
+		for {
+			select {
+			case <-ctx.Done():
+				self.stop.Store(true)
+				return
+			case <-ponderhit:
+				start.Store(time.Now().UnixNano())
+				set_deadline()
+				waiting.Store(false)
+				ponderhit = nil
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	wait := func() {
+		if waiting.Load() {
+			select {
+			case <-ctx.Done():
+			case <-limits.PonderHit:
+			}
+		}
+	}
+
+	// There can't be more lines than moves to search
+	legal := pos.legal_moves()
+	self.multi_pv = 0
+	for _, m := range legal {
+		if self.workers[0].root_allows(m) {
+			self.multi_pv++
+		}
+	}
+	self.multi_pv = max(1, min(self.multi_pv, self.max_pv))
+
+	if len(legal) == 0 {
+		best := Info{Bound: BOUND_EXACT}
+		if pos.in_check() {
+			best.Score = -(MATE_UPPER - 2)
+		}
+		info(best)
+		wait()
+		return best
+	}
+
+	self.tt.new_search()
+	if len(self.searchmoves) > 0 {
+		self.forget_root(pos)
+	}
+
+	var helpers sync.WaitGroup
+	for _, w := range self.workers[1:] {
+		helpers.Add(1)
+		go func(w *worker) {
+			defer helpers.Done()
+			w.search(pos, func(r search_result) bool {
+				return false
+			})
+		}(w)
+	}
+
+	var best Info
+	stop := false
+
+	// The lines of a depth are found best first, but a later one may score
+	// higher once searched on its own, so they are reported sorted when the
+	// depth is done, or the search stops.
+	lines := []Info{}
+	report := func() {
+		sort.SliceStable(lines, func(a, b int) bool {
+			return lines[a].Score > lines[b].Score
+		})
+		for j := range lines {
+			lines[j].MultiPV = j + 1
+			info(lines[j])
+		}
+		if len(lines) > 0 {
+			best = lines[0]
+		}
+		lines = lines[:0]
+	}
+
+	self.workers[0].search(pos, func(r search_result) bool {
+		i := Info{
+			Depth:    r.depth,
+			MultiPV:  r.multipv,
+			Move:     r.move,
+			Score:    r.score,
+			Bound:    r.bound,
+			Nodes:    r.nodes,
+			Time:     elapsed(),
+			HashFull: self.tt.hashfull(),
+		}
+		if !white_turn && i.Move != (Move{}) {
+			i.Move = i.Move.rotate()
+		}
+		for j, m := range r.pv {
+			if white_turn != (j%2 == 0) {
+				m = m.rotate()
+			}
+			i.PV = append(i.PV, m)
+		}
+
+		if r.bound != BOUND_EXACT {
+			info(i)
+			return false
+		}
+		lines = append(lines, i)
+
+		// The first line decides, but the other lines of its depth are
+		// still searched
+		if r.multipv == 1 {
+			mate, is_mate := mate_moves(r.score)
+
+			stop = !waiting.Load() && (r.depth >= setting_max_depth ||
+				(limits.Depth > 0 && r.depth >= limits.Depth) ||
+				(limits.Nodes > 0 && r.nodes >= limits.Nodes) ||
+				(limits.Mate > 0 && ((is_mate && 0 < mate && mate <= limits.Mate) || r.depth > 2*limits.Mate)) ||
+				(tm != nil && tm.stop_after(r, i.Time.Milliseconds())))
+		}
+
+		if r.multipv < self.multi_pv {
+			return false
+		}
+		report()
+		return stop
+	})
+	report()
+
+	self.stop.Store(true)
+	helpers.Wait()
+	if len(self.searchmoves) > 0 {
+		self.forget_root(pos)
+	}
+
+	wait()
+	return best
+}
@@ -0,0 +1,182 @@
+package fish
+
+import "sort"
+
+// Tuning of the 2024 strategy. Quiescence search takes the moves worth at
+// least setting_qs - depth*setting_qs_a.
+var setting_qs = 40
+var setting_qs_a = 140
+var setting_eval_roughness_2024 = 15
+
+// strategy_2024 is the search of the 2024 Sunfish port: null move only near
+// equality, internal iterative deepening, futility pruning at the horizon
+// and a depth dependent quiescence limit. Each depth starts testing from
+// where the previous one ended.
+type strategy_2024 struct{}
+
+func (strategy_2024) bound(w *worker, pos *Position, gamma int, depth int, root bool) int {
+	return w.bound_2024(pos, gamma, depth, root, !root)
+}
+
+func (strategy_2024) window(last int) (lower, upper, gamma int) {
+	return -MATE_LOWER, MATE_LOWER, last
+}
+
+func (strategy_2024) narrow(lower, upper int) bool {
+	return lower >= upper-setting_eval_roughness_2024
+}
+
+// bound_2024 is bound() as the 2024 port does it. can_null is false at the
+// root, after a null move and for internal iterative deepening.
+func (self *worker) bound_2024(pos *Position, gamma int, depth int, root bool, can_null bool) int {
+	depth = max(depth, 0)
+
+	// As in the original, the table keeps apart the searches with and
+	// without the null move, so that IID and the searches after a null
+	// move don't replace the full ones. The root has no null move, so its
+	// flag is set like in the default strategy.
+	flag := !can_null
+	entry, use_tt, score, done := self.enter(pos, gamma, depth, root, flag)
+	if done {
+		return score
+	}
+
+	n := len(self.history)
+	self.history = append(self.history, pos.hash)
+	defer func() {
+		self.history = self.history[:n]
+	}()
+
+	child_gamma := 1 - mate_undecay(gamma)
+	child := func(child_pos *Position, child_depth int, child_can_null bool) int {
+		return mate_decay(-self.bound_2024(child_pos, child_gamma, child_depth, false, child_can_null))
+	}
+
+	// The stand pat, and what futility pruning adds the moves to
+	static := pos.score
+	if depth <= 1 {
+		static = self.evaluate(pos)
+	}
+
+	moves := func(yield func(sm score_move) bool) {
+		if depth > 2 && can_null && abs(pos.score) < 500 {
+			if yield(score_move{
+				score: child(pos.nullmove(), depth-3, false),
+			}) {
+				return
+			}
+		}
+
+		if depth == 0 {
+			if yield(score_move{
+				score: static,
+			}) {
+				return
+			}
+		}
+
+		killer, killer_found := self.tt.probe_move(pos.hash)
+		if !use_tt {
+			killer, killer_found = self.root_move, self.root_move != (Move{})
+		}
+
+		// Without a hash move, find one with a shallower search. It is
+		// the same node, so it isn't on the path twice.
+		if !killer_found && depth > 2 {
+			self.history = self.history[:n]
+			self.bound_2024(pos, gamma, depth-3, root, false)
+			self.history = append(self.history, pos.hash)
+			if use_tt {
+				killer, killer_found = self.tt.probe_move(pos.hash)
+			} else {
+				killer, killer_found = self.root_move, self.root_move != (Move{})
+			}
+		}
+
+		val_lower := setting_qs - depth*setting_qs_a
+
+		if killer_found && pos.value(killer) >= val_lower && (!root || self.root_allows(killer)) {
+			if yield(score_move{
+				move:  killer,
+				score: child(pos.move(killer), depth-1, true),
+			}) {
+				return
+			}
+		}
+
+		sorted_moves := make([]Move, 0, 64)
+		pos.gen_moves(func(m Move) bool {
+			sorted_moves = append(sorted_moves, m)
+			return false
+		})
+
+		sort.Slice(sorted_moves, func(i, j int) bool {
+			return pos.value(sorted_moves[i]) > pos.value(sorted_moves[j])
+		})
+
+		for _, move := range sorted_moves {
+			if root && !self.root_allows(move) {
+				continue
+			}
+
+			val := pos.value(move)
+			if val < val_lower {
+				break
+			}
+
+			// Futility: the opponent would stand pat below gamma anyway.
+			// Moves are sorted, so the rest can't do better.
+			if depth <= 1 && static+val < gamma {
+				score := static + val
+				if val >= MATE_LOWER {
+					score = mate_decay(MATE_UPPER)
+				}
+				yield(score_move{
+					move:  move,
+					score: score,
+				})
+				return
+			}
+
+			if yield(score_move{
+				move:  move,
+				score: child(pos.move(move), depth-1, true),
+			}) {
+				return
+			}
+		}
+	}
+
+	best := -MATE_UPPER
+	moves(func(sm score_move) bool {
+		if self.aborted() {
+			return true
+		}
+
+		best = max(best, sm.score)
+		if best >= gamma {
+			self.cutoff(pos, depth, root, flag, use_tt, sm.move)
+
+			return true
+		}
+
+		return false
+	})
+
+	if self.aborted() {
+		return best
+	}
+
+	// Mated or stalemated, when no move is legal. A null move can hide
+	// that every real move loses the king, so any losing score is
+	// checked. Too expensive near the horizon.
+	if depth > 2 && best < gamma && best < 0 {
+		if score, stuck := pos.stuck_score(); stuck {
+			best = score
+		}
+	}
+
+	self.leave(pos, gamma, depth, flag, use_tt, entry, best)
+
+	return best
+}
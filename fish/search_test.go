@@ -0,0 +1,159 @@
+package fish
+
+import (
+	"context"
+	"testing"
+)
+
+// A middlegame position with plenty of moves, for node counts
+const FEN_ITALIAN = "r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3"
+
+func TestSearchThreads(t *testing.T) {
+	pos, _ := NewPosition(FEN_ITALIAN)
+
+	searcher := NewSearcher()
+	searcher.SetThreads(4)
+	nodes := 0
+	best := searcher.Search(context.Background(), pos, Limits{Depth: 5}, func(info Info) {
+		nodes = info.Nodes
+	})
+
+	if !pos.is_legal(best.Move) {
+		t.Errorf("best move %v is not legal", best.Move)
+	}
+	for _, w := range searcher.workers {
+		if w.nodes.Load() == 0 {
+			t.Errorf("worker %d did not search", w.id)
+		}
+	}
+	if nodes <= int(searcher.workers[0].nodes.Load()) {
+		t.Errorf("nodes %d do not include the helpers", nodes)
+	}
+}
+
+func TestSearchMultiPV(t *testing.T) {
+	pos, _ := NewPosition(FEN_INITIAL)
+	searcher := NewSearcher()
+	searcher.SetMultiPV(3)
+	lines := map[int]Info{}
+	searcher.Search(context.Background(), pos, Limits{Depth: 4}, func(info Info) {
+		if info.Bound == BOUND_EXACT && info.Depth == 4 {
+			lines[info.MultiPV] = info
+		}
+	})
+
+	if len(lines) != 3 || lines[1].Move == lines[2].Move || lines[1].Move == lines[3].Move || lines[2].Move == lines[3].Move {
+		t.Errorf("lines = %v", lines)
+	}
+	if lines[1].Score < lines[2].Score || lines[2].Score < lines[3].Score {
+		t.Errorf("lines are not sorted by score: %v", lines)
+	}
+
+	// Other searchers keep a single line
+	NewSearcher().Search(context.Background(), pos, Limits{Depth: 2}, func(info Info) {
+		if info.MultiPV > 1 {
+			t.Errorf("line %d without MultiPV", info.MultiPV)
+		}
+	})
+
+	// A lone legal move makes a single line
+	pos, _ = NewPosition("7k/8/8/8/8/8/6q1/7K w - - 0 1")
+	searcher.Search(context.Background(), pos, Limits{Depth: 2}, func(info Info) {
+		if info.MultiPV > 1 {
+			t.Errorf("line %d of a single move", info.MultiPV)
+		}
+	})
+}
+
+func TestStrategies(t *testing.T) {
+	nodes := map[int]string{}
+	for _, name := range STRATEGIES {
+		searcher := NewSearcher()
+		if err := searcher.SetStrategy(name); err != nil {
+			t.Fatal(err)
+		}
+
+		// Each strategy searches a tree of its own
+		pos, _ := NewPosition(FEN_ITALIAN)
+		best := searcher.Search(context.Background(), pos, Limits{Depth: 5}, func(info Info) {})
+		if other, found := nodes[best.Nodes]; found {
+			t.Errorf("%s searched the same %d nodes as %s", name, best.Nodes, other)
+		}
+		nodes[best.Nodes] = name
+
+		// Kf8 is the only move that leaves black no move, and it is
+		// stalemate, not mate
+		pos, _ = NewPosition("4K2k/R7/8/8/8/8/8/8 w - - 0 1")
+		best = searcher.Search(context.Background(), pos, Limits{Depth: 5}, func(info Info) {})
+		if best.Move.String() == "e8f8" {
+			t.Errorf("%s: stalemates with %v", name, best.Move)
+		}
+		if _, ok := best.Mate(); ok {
+			t.Errorf("%s: %v scores mate %v", name, best.Move, best.Score)
+		}
+
+		// Inside the tree, where the root's check for legal moves doesn't
+		// help
+		pos, _ = NewPosition("7k/8/5KQ1/8/8/8/8/8 b - - 0 1")
+		w := &worker{searcher: searcher, tt: searcher.tt}
+		for depth := 3; depth <= 5; depth++ {
+			if score := searcher.strategy.bound(w, pos, 1, depth, false); score != 0 {
+				t.Errorf("%s: stalemate scores %d at depth %d", name, score, depth)
+			}
+		}
+	}
+
+	if err := NewSearcher().SetStrategy("nope"); err == nil {
+		t.Errorf("unknown strategy accepted")
+	}
+}
+
+func TestDraws(t *testing.T) {
+	// The knights go back home, and going back once more repeats
+	pos, _ := NewPosition(FEN_INITIAL)
+	for _, str := range []string{"g1f3", "g8f6", "f3g1", "f6g8", "g1f3", "g8f6", "f3g1"} {
+		move, _ := ParseMove(str)
+		pos, _ = pos.Play(move)
+	}
+	move, _ := ParseMove("f6g8")
+	best := NewSearcher().Search(context.Background(), pos, Limits{Depth: 4, SearchMoves: []Move{move}}, func(info Info) {})
+	if best.Move != move || best.Score != 0 {
+		t.Errorf("repetition: best = %v %v", best.Move, best.Score)
+	}
+
+	// Mate on the hundredth ply wins, it is no fifty move draw
+	pos, _ = NewPosition("6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 99 80")
+	best = NewSearcher().Search(context.Background(), pos, Limits{Depth: 4}, func(info Info) {})
+	if moves, ok := best.Mate(); !ok || moves != 1 || best.Move.String() != "d1d8" {
+		t.Errorf("fifty moves: best = %v %v", best.Move, best.Score)
+	}
+}
+
+func TestLateMoveReductions(t *testing.T) {
+	defer func(lmr bool) { setting_lmr = lmr }(setting_lmr)
+
+	nodes := [2]int{}
+	for i, lmr := range []bool{false, true} {
+		setting_lmr = lmr
+		pos, _ := NewPosition(FEN_ITALIAN)
+		nodes[i] = NewSearcher().Search(context.Background(), pos, Limits{Depth: 6}, func(info Info) {}).Nodes
+	}
+	if nodes[1] >= nodes[0]*4/5 {
+		t.Errorf("nodes %d with LMR, %d without", nodes[1], nodes[0])
+	}
+}
+
+// A smothered mate in 4 is 7 plies deep, but every white move is a check.
+func TestCheckExtensions(t *testing.T) {
+	defer func(extensions bool) { setting_check_extensions = extensions }(setting_check_extensions)
+
+	for _, extensions := range []bool{false, true} {
+		setting_check_extensions = extensions
+		pos, _ := NewPosition("2r4k/6pp/8/4N3/8/1Q6/8/6K1 w - - 0 1")
+		best := NewSearcher().Search(context.Background(), pos, Limits{Depth: 4}, func(info Info) {})
+		moves, ok := best.Mate()
+		if found := ok && moves == 4 && best.Move.String() == "e5f7"; found != extensions {
+			t.Errorf("extensions %v: best = %v %v", extensions, best.Move, best.Score)
+		}
+	}
+}
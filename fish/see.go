@@ -0,0 +1,127 @@
+package fish
+
+// attacker finds the least valuable piece of one side attacking sq, on a
+// board seen from the side to move. ours picks the upper case pieces. It
+// returns 0 when there is none.
+func attacker(board *mailbox, sq int, ours bool) int {
+	is_side := func(p piece) bool {
+		if ours {
+			return p.isupper()
+		}
+		return p.islower()
+	}
+	piece_at := func(i int, kind piece) bool {
+		p := board[i]
+		return is_side(p) && p&^piece_is_lower == kind
+	}
+
+	// Our pawns capture north, theirs south
+	pawn_from := [2]int{sq + south + west, sq + south + east}
+	if !ours {
+		pawn_from = [2]int{sq + north + west, sq + north + east}
+	}
+	for _, i := range pawn_from {
+		if piece_at(i, piece_p) {
+			return i
+		}
+	}
+
+	for _, d := range directions[piece_n] {
+		if piece_at(sq+d, piece_n) {
+			return sq + d
+		}
+	}
+
+	// The first piece along each ray, kept by kind of ray
+	sliders := [piece_k + 1]int{}
+	for k, d := range directions[piece_q] {
+		i := sq + d
+		for board[i] == piece_is_empty {
+			i += d
+		}
+		if !is_side(board[i]) {
+			continue
+		}
+
+		p := board[i] &^ piece_is_lower
+		diagonal := k >= 4
+		if (p == piece_b && diagonal) || (p == piece_r && !diagonal) || p == piece_q {
+			if sliders[p] == 0 {
+				sliders[p] = i
+			}
+		}
+		if p == piece_k && i == sq+d && sliders[piece_k] == 0 {
+			sliders[piece_k] = i
+		}
+	}
+	for _, p := range []int{piece_b, piece_r, piece_q, piece_k} {
+		if sliders[p] != 0 {
+			return sliders[p]
+		}
+	}
+
+	return 0
+}
+
+// see is the material the side to move wins with move when both sides keep
+// capturing on its square with their least valuable piece, and may stop at
+// any time. It is 0 for a quiet move to a safe square.
+func (self *Position) see(move Move) int {
+	board := self.board
+	from, to := move.from, move.to
+	p := board[from]
+
+	gain := [40]int{}
+	if q := board[to]; q.islower() {
+		gain[0] = piece_value[q.swapcase()]
+	}
+	if p == piece_p && to == self.ep {
+		gain[0] = piece_value[piece_p]
+		board[to+south] = piece_is_empty
+	}
+
+	// The piece standing on the square, the next one to be taken
+	on_square := piece_value[p]
+	if move.prom != 0 {
+		gain[0] += piece_value[move.prom] - piece_value[piece_p]
+		on_square = piece_value[move.prom]
+	}
+	board[from] = piece_is_empty
+	board[to] = p
+
+	d := 0
+	ours := false
+	for d+1 < len(gain) {
+		d++
+		gain[d] = on_square - gain[d-1]
+		if max(-gain[d-1], gain[d]) < 0 {
+			break
+		}
+
+		i := attacker(&board, to, ours)
+		if i == 0 {
+			break
+		}
+		on_square = piece_value[board[i]&^piece_is_lower]
+		board[to] = board[i]
+		board[i] = piece_is_empty
+		ours = !ours
+	}
+
+	for d--; d > 0; d-- {
+		gain[d-1] = -max(-gain[d-1], gain[d])
+	}
+
+	return gain[0]
+}
+
+// SEE is the static exchange evaluation of move from white's point of view,
+// like the moves of LegalMoves: the material its side wins, in centipawns,
+// if both sides keep capturing on the target square. A negative value means
+// the piece is lost, so SEE tells whether a capture or a square is safe.
+func (self *Position) SEE(move Move) int {
+	if !self.white_turn() {
+		move = move.rotate()
+	}
+	return self.see(move)
+}
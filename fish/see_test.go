@@ -0,0 +1,36 @@
+package fish
+
+import "testing"
+
+func TestSEE(t *testing.T) {
+	tests := []struct {
+		fen  string
+		move string
+		see  int
+	}{
+		// Undefended pawn
+		{"1k1r4/1pp4p/p7/4p3/8/P5P1/1PP4P/2K1R3 w - - 0 1", "e1e5", 100},
+		// Knight for a pawn: white stops after NxN, the rest only gets worse
+		{"1k1r3q/1ppn3p/p4b2/4p3/8/P2N2P1/1PP1R1BP/2K1Q3 w - - 0 1", "d3e5", 100 - 280},
+		// A safe square, and one the queen is lost on
+		{"4k3/8/8/3p4/8/8/8/4K2Q w - - 0 1", "h1h4", 0},
+		{"4k3/8/8/3p4/8/8/8/4K2Q w - - 0 1", "h1e4", -929},
+		// Black to move, with and without a recapture
+		{"4k3/8/8/3p4/4P3/8/8/4K3 b - - 0 1", "d5e4", 100},
+		{"4k3/8/8/3p4/4P3/5K2/8/8 b - - 0 1", "d5e4", 0},
+		// En passant, and a promotion the rook can't stop
+		{"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "e5d6", 100},
+		{"r3k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7a8q", 479 + 929 - 100},
+	}
+
+	for _, test := range tests {
+		pos, err := NewPosition(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		move, _ := ParseMove(test.move)
+		if see := pos.SEE(move); see != test.see {
+			t.Errorf("%s %s: SEE = %d, want %d", test.fen, test.move, see, test.see)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package fish
+
+import "fmt"
+
+// Setting is a knob of the search or the eval, for engines to offer as an
+// option. A Check setting is a switch, 0 or 1.
+type Setting struct {
+	Name              string
+	Check             bool
+	Default, Min, Max int
+}
+
+// settings are shared by every Searcher, so they are only changed between
+// searches.
+var settings = []struct {
+	Setting
+	spin  *int
+	check *bool
+}{
+	{Setting: Setting{Name: "Move Overhead", Max: 5000}, spin: &setting_move_overhead},
+	{Setting: Setting{Name: "SETTING_MAX_DEPTH", Min: 1, Max: 9999}, spin: &setting_max_depth},
+	{Setting: Setting{Name: "SETTING_QS_LIMIT", Min: 1, Max: 9999}, spin: &setting_qs_limit},
+	{Setting: Setting{Name: "SETTING_EVAL_ROUGHNESS", Min: 1, Max: 9999}, spin: &setting_eval_roughness},
+	{Setting: Setting{Name: "SETTING_CHECK_EXTENSIONS"}, check: &setting_check_extensions},
+	{Setting: Setting{Name: "SETTING_LMR"}, check: &setting_lmr},
+	{Setting: Setting{Name: "SETTING_LMR_MIN_DEPTH", Min: 1, Max: 99}, spin: &setting_lmr_min_depth},
+	{Setting: Setting{Name: "SETTING_LMR_MIN_MOVES", Max: 99}, spin: &setting_lmr_min_moves},
+	{Setting: Setting{Name: "SETTING_SEE"}, check: &setting_see},
+	{Setting: Setting{Name: "SETTING_EVAL_TERMS"}, check: &setting_eval_terms},
+	{Setting: Setting{Name: "SETTING_QS", Max: 300}, spin: &setting_qs},
+	{Setting: Setting{Name: "SETTING_QS_A", Max: 300}, spin: &setting_qs_a},
+	{Setting: Setting{Name: "SETTING_EVAL_ROUGHNESS_2024", Min: 1, Max: 9999}, spin: &setting_eval_roughness_2024},
+}
+
+func init() {
+	for i := range settings {
+		s := &settings[i]
+		if s.check != nil {
+			s.Check, s.Max = true, 1
+			if *s.check {
+				s.Default = 1
+			}
+			continue
+		}
+		s.Default = *s.spin
+	}
+}
+
+// Settings lists the settings, with their defaults and ranges.
+func Settings() []Setting {
+	result := make([]Setting, len(settings))
+	for i, s := range settings {
+		result[i] = s.Setting
+	}
+	return result
+}
+
+// SetSetting changes a setting of every Searcher. It must not be called
+// while one is searching.
+func SetSetting(name string, value int) error {
+	for _, s := range settings {
+		if s.Name != name {
+			continue
+		}
+		if value < s.Min || value > s.Max {
+			return fmt.Errorf("setting %s must be between %d and %d [%d]", name, s.Min, s.Max, value)
+		}
+		if s.check != nil {
+			*s.check = value != 0
+		} else {
+			*s.spin = value
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown setting [%s]", name)
+}
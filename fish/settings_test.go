@@ -0,0 +1,25 @@
+package fish
+
+import "testing"
+
+func TestSettings(t *testing.T) {
+	defer func(lmr bool, depth int) {
+		setting_lmr, setting_lmr_min_depth = lmr, depth
+	}(setting_lmr, setting_lmr_min_depth)
+
+	for _, s := range Settings() {
+		if s.Default < s.Min || s.Default > s.Max {
+			t.Errorf("%s: default %d out of [%d, %d]", s.Name, s.Default, s.Min, s.Max)
+		}
+	}
+
+	if err := SetSetting("SETTING_LMR", 0); err != nil || setting_lmr {
+		t.Errorf("SETTING_LMR is still on: %v", err)
+	}
+	if err := SetSetting("SETTING_LMR_MIN_DEPTH", 5); err != nil || setting_lmr_min_depth != 5 {
+		t.Errorf("SETTING_LMR_MIN_DEPTH = %d: %v", setting_lmr_min_depth, err)
+	}
+	if SetSetting("SETTING_LMR_MIN_DEPTH", 0) == nil || SetSetting("nope", 1) == nil {
+		t.Errorf("bad settings accepted")
+	}
+}
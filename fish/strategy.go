@@ -0,0 +1,53 @@
+package fish
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A strategy is one way of searching: the bound() of each node, and how the
+// MTD-bi loop of each depth narrows the window. Strategies share everything
+// else: move generation, the table, limits, MultiPV and threads.
+type strategy interface {
+	bound(w *worker, pos *Position, gamma int, depth int, root bool) int
+
+	// window returns the score window a depth starts with, and the first
+	// gamma to try. last is the gamma the previous depth ended on.
+	window(last int) (lower, upper, gamma int)
+
+	// narrow tells when the window is good enough to stop testing.
+	narrow(lower, upper int) bool
+}
+
+const STRATEGY_DEFAULT = "default"
+const STRATEGY_2024 = "2024"
+
+// STRATEGIES are the names SetStrategy takes, the default first.
+var STRATEGIES = []string{STRATEGY_DEFAULT, STRATEGY_2024}
+
+func find_strategy(name string) (strategy, error) {
+	switch strings.ToLower(name) {
+	case STRATEGY_DEFAULT:
+		return strategy_default{}, nil
+	case STRATEGY_2024:
+		return strategy_2024{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown search strategy [%s]", name)
+}
+
+// strategy_default is the search of worker.bound: null move whenever there
+// are pieces, and exact mate and stalemate scores through is_dead.
+type strategy_default struct{}
+
+func (strategy_default) bound(w *worker, pos *Position, gamma int, depth int, root bool) int {
+	return w.bound(pos, gamma, depth, root)
+}
+
+func (strategy_default) window(last int) (lower, upper, gamma int) {
+	return -MATE_UPPER, MATE_UPPER, 0
+}
+
+func (strategy_default) narrow(lower, upper int) bool {
+	return lower >= upper-setting_eval_roughness && !(lower < upper && is_mate_window(lower, upper))
+}
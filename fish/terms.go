@@ -0,0 +1,226 @@
+package fish
+
+// Evaluation terms the piece-square tables can't see: pawn structure,
+// mobility and king safety. They need the whole board, so unlike pos.score
+// they are computed from scratch, only for the stand pat of the quiescence
+// search. The pawn structure goes through a pawn hash, keyed by the pawn
+// hash of the Position.
+
+// setting_eval_terms adds the terms to pos.score at the quiescence leaves.
+var setting_eval_terms = true
+
+// Pawn structure, by rank from the side of the pawn (index 1 is rank 2)
+var passed_mg = [8]int{0, 0, 5, 10, 20, 35, 60, 0}
+var passed_eg = [8]int{0, 5, 10, 20, 35, 55, 80, 0}
+
+const doubled_mg, doubled_eg = 10, 20
+const isolated_mg, isolated_eg = 10, 15
+
+// Mobility per square reached, above the usual number of squares
+var mobility_mg = [6]int{0, 4, 5, 2, 1, 0}
+var mobility_eg = [6]int{0, 4, 5, 4, 2, 0}
+var mobility_base = [6]int{0, 4, 6, 7, 13, 0}
+
+// King safety, middlegame only: pawns in front of the king, open files next
+// to it, and the weight of the pieces attacking the squares around it.
+const shield_near, shield_far, shield_open = 15, 8, 15
+const king_attack_max = 300
+
+var attack_weight = [6]int{0, 2, 2, 3, 5, 0}
+
+// pawn_table_entries is the size of the pawn hash of each worker. Pawns
+// move little, so most probes hit even in a small table.
+const pawn_table_entries = 1 << 14
+
+type pawn_entry struct {
+	key    uint64
+	mg, eg int
+}
+
+// evaluate is the score of pos for the stand pat: pos.score plus the terms,
+// from the side to move.
+func (self *worker) evaluate(pos *Position) int {
+	if !setting_eval_terms {
+		return pos.score
+	}
+
+	board := &pos.board
+
+	mg, eg := self.pawn_terms(pos)
+	mg_us, eg_us := piece_terms(board, false)
+	mg_them, eg_them := piece_terms(board, true)
+
+	return pos.score + taper(mg+mg_us-mg_them, eg+eg_us-eg_them, pos.phase)
+}
+
+func rotate_board(board *mailbox) mailbox {
+	var rotated mailbox
+	for i, p := range board {
+		rotated[119-i] = p.swapcase()
+	}
+	return rotated
+}
+
+// pawn_terms scores the pawn structure of both sides, through the pawn hash.
+// A board with no pawns has key 0 and scores 0, like an empty entry.
+func (self *worker) pawn_terms(pos *Position) (mg, eg int) {
+	key := pos.pawn_hash
+
+	if self.pawns == nil {
+		self.pawns = make([]pawn_entry, pawn_table_entries)
+	}
+	entry := &self.pawns[key%pawn_table_entries]
+	if entry.key != key {
+		rotated := rotate_board(&pos.board)
+		mg_us, eg_us := pawn_structure(&pos.board)
+		mg_them, eg_them := pawn_structure(&rotated)
+		*entry = pawn_entry{key, mg_us - mg_them, eg_us - eg_them}
+	}
+
+	return entry.mg, entry.eg
+}
+
+// pawn_structure scores the passed, doubled and isolated pawns of the upper
+// case side.
+func pawn_structure(board *mailbox) (mg, eg int) {
+	// Our pawns per file, and the row of their pawn furthest north
+	ours := [10]int{}
+	their_front := [10]int{}
+	for f := range their_front {
+		their_front[f] = 10
+	}
+	for i, p := range board {
+		if p == piece_p {
+			ours[i%10]++
+		}
+		if p == piece_p|piece_is_lower && i/10 < their_front[i%10] {
+			their_front[i%10] = i / 10
+		}
+	}
+
+	for i, p := range board {
+		if p != piece_p {
+			continue
+		}
+		row, f := i/10, i%10
+
+		if ours[f-1] == 0 && ours[f+1] == 0 {
+			mg -= isolated_mg
+			eg -= isolated_eg
+		}
+
+		// A pawn level with it on the next file can't stop it
+		if their_front[f-1] >= row && their_front[f] >= row && their_front[f+1] >= row {
+			rank := 9 - row
+			mg += passed_mg[rank]
+			eg += passed_eg[rank]
+		}
+	}
+
+	for f := 1; f <= 8; f++ {
+		if ours[f] > 1 {
+			mg -= doubled_mg * (ours[f] - 1)
+			eg -= doubled_eg * (ours[f] - 1)
+		}
+	}
+
+	return mg, eg
+}
+
+// piece_terms scores the mobility of the upper case pieces, or of the lower
+// case ones, the pawns in front of their king, and their attacks on the other
+// king. Squares the other pawns guard don't count as mobility.
+func piece_terms(board *mailbox, lower bool) (mg, eg int) {
+	us, them, forward := piece(0), piece(piece_is_lower), north
+	if lower {
+		us, them, forward = them, us, south
+	}
+
+	king, their_king := 0, 0
+	for i, p := range board {
+		if p == piece_k|us {
+			king = i
+		}
+		if p == piece_k|them {
+			their_king = i
+		}
+	}
+	// Only the stand pat of a lost king gets here, and that scores mate
+	if king == 0 || their_king == 0 {
+		return 0, 0
+	}
+
+	zone := [120]bool{}
+	zone[their_king] = true
+	for _, d := range directions[piece_k] {
+		zone[their_king+d] = true
+	}
+
+	attackers, attack := 0, 0
+	for i, p := range board {
+		kind := p &^ piece_is_lower
+		if !p.is_side(us) || kind == piece_p || kind == piece_k {
+			continue
+		}
+
+		squares, attacks := 0, false
+		for _, d := range directions[kind] {
+			for j := i + d; ; j += d {
+				q := board[j]
+				if q.is_invalid_space() {
+					break
+				}
+				attacks = attacks || zone[j]
+				if q.is_side(us) {
+					break
+				}
+				if board[j+forward+west] != piece_p|them && board[j+forward+east] != piece_p|them {
+					squares++
+				}
+				if q != piece_is_empty || kind == piece_n {
+					break
+				}
+			}
+		}
+
+		mg += mobility_mg[kind] * (squares - mobility_base[kind])
+		eg += mobility_eg[kind] * (squares - mobility_base[kind])
+		if attacks {
+			attackers++
+			attack += attack_weight[kind]
+		}
+	}
+	if attackers >= 2 {
+		mg += min(attack*attack, king_attack_max)
+	}
+
+	// The shield only counts for a king on its first two ranks
+	first_ranks := king >= a1+north
+	if lower {
+		first_ranks = king <= h8+south
+	}
+	if first_ranks {
+		for f := king - 1; f <= king+1; f++ {
+			switch {
+			case board[f].is_invalid_space():
+			case board[f+forward] == piece_p|us:
+				mg += shield_near
+			case board[f+forward+forward] == piece_p|us:
+				mg += shield_far
+			case !file_has_pawn(board, f%10, piece_p|us):
+				mg -= shield_open
+			}
+		}
+	}
+
+	return mg, eg
+}
+
+func file_has_pawn(board *mailbox, f int, pawn piece) bool {
+	for i := a8 - 1 + f; i <= a1+f; i += south {
+		if board[i] == pawn {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package fish
+
+import "testing"
+
+func TestEvalTerms(t *testing.T) {
+	defer func(on bool) { setting_eval_terms = on }(setting_eval_terms)
+	setting_eval_terms = true
+
+	w := &worker{}
+	terms := func(fen string) int {
+		pos, _ := parseFEN(fen)
+		return w.evaluate(pos) - pos.score
+	}
+	pawns := func(fen string) int {
+		pos, _ := parseFEN(fen)
+		mg, _ := pawn_structure(&pos.board)
+		return mg
+	}
+
+	// The terms of one side are those of the other, negated. The second
+	// time round they come from the pawn hash.
+	for i := 0; i < 2; i++ {
+		for _, p := range perftPositions {
+			pos, _ := parseFEN(p.fen)
+			if a, b := terms(p.fen), w.evaluate(pos.rotate())-pos.rotate().score; a != -b {
+				t.Errorf("%s: terms %d, rotated %d", p.fen, a, b)
+			}
+		}
+	}
+
+	// The lower case pieces score as the upper case ones of the rotated
+	// board
+	for _, p := range perftPositions {
+		pos, _ := parseFEN(p.fen)
+		rotated := rotate_board(&pos.board)
+		mg, eg := piece_terms(&pos.board, true)
+		if mg_r, eg_r := piece_terms(&rotated, false); mg != mg_r || eg != eg_r {
+			t.Errorf("%s: lower case terms %d %d, rotated %d %d", p.fen, mg, eg, mg_r, eg_r)
+		}
+	}
+
+	cases := []struct {
+		name          string
+		eval          func(fen string) int
+		better, worse string
+	}{
+		{"passed pawn", pawns, "6k1/8/8/3P4/8/8/8/6K1 w - - 0 1", "6k1/4p3/8/3P4/8/8/8/6K1 w - - 0 1"},
+		{"doubled pawns", pawns, "6k1/8/8/8/8/8/2PP4/6K1 w - - 0 1", "6k1/8/8/8/8/3P4/3P4/6K1 w - - 0 1"},
+		{"pawn shield", terms, "3q2k1/5ppp/8/8/8/8/5PPP/3Q2K1 w - - 0 1", "3q2k1/5ppp/8/8/8/5PPP/8/3Q2K1 w - - 0 1"},
+		{"mobility", terms, "6k1/8/8/8/3N4/8/8/6K1 w - - 0 1", "6k1/8/8/8/8/8/8/N5K1 w - - 0 1"},
+	}
+	for _, c := range cases {
+		if better, worse := c.eval(c.better), c.eval(c.worse); better <= worse {
+			t.Errorf("%s: %d should beat %d", c.name, better, worse)
+		}
+	}
+
+	setting_eval_terms = false
+	if terms(perftPositions[1].fen) != 0 {
+		t.Errorf("with the terms off the stand pat should be pos.score")
+	}
+}
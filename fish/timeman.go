@@ -0,0 +1,108 @@
+package fish
+
+// Moves we expect to still play when the time control has no movestogo.
+const time_sudden_death_moves = 30
+
+// time_manager decides when to stop iterative deepening. The soft limit is the
+// time we would like to use and is checked between iterations; it grows when
+// the best move keeps changing. The hard limit is never exceeded: the searcher
+// aborts the running iteration when it is reached.
+type time_manager struct {
+	soft, hard   int64 // milliseconds
+	fixed        bool  // movetime: always use the whole budget
+	last_move    Move
+	last_elapsed int64
+	last_iter    int64
+	instability  float64
+}
+
+func new_time_manager(time_left, inc, movestogo, movetime int) *time_manager {
+	if movetime > 0 {
+		budget := int64(max(0, movetime-setting_move_overhead))
+		return &time_manager{soft: budget, hard: budget, fixed: true}
+	}
+
+	if time_left <= 0 {
+		// Only the increment is left to spend, and at least the overhead
+		hard := max(setting_move_overhead, inc-setting_move_overhead)
+		soft := max(setting_move_overhead, min(inc*3/4, hard))
+		return &time_manager{soft: int64(soft), hard: int64(hard)}
+	}
+
+	max_use := max(0, time_left-setting_move_overhead)
+
+	moves := time_sudden_death_moves
+	if movestogo > 0 {
+		moves = movestogo
+	}
+
+	soft := time_left/moves + inc*3/4
+	hard := soft * 4
+	if moves > 1 {
+		// Never bet more than half of the clock on one move
+		hard = min(hard, max_use/2)
+	}
+	hard = min(hard, max_use)
+	soft = min(soft, hard)
+
+	return &time_manager{soft: int64(soft), hard: int64(hard)}
+}
+
+// stop_after is called with each completed iteration and the time used so
+// far. It returns true when the search should not start another iteration.
+func (self *time_manager) stop_after(r search_result, elapsed_ms int64) bool {
+	if self.fixed {
+		return elapsed_ms >= self.hard
+	}
+
+	iter := max64(elapsed_ms-self.last_elapsed, 1)
+
+	// Time to the next iteration grows by about the effective branching factor
+	branching := 3.0
+	if self.last_iter > 0 {
+		branching = float64(iter) / float64(self.last_iter)
+		branching = minf(maxf(branching, 1.5), 5)
+	}
+
+	self.instability *= 0.5
+	if r.depth > 1 && r.move != self.last_move {
+		self.instability += 1
+	}
+
+	self.last_move = r.move
+	self.last_elapsed = elapsed_ms
+	self.last_iter = iter
+
+	soft := min64(int64(float64(self.soft)*(1+self.instability)), self.hard)
+	next := elapsed_ms + int64(float64(iter)*branching)
+
+	return elapsed_ms >= soft || next > self.hard
+}
+
+func max64(x, y int64) int64 {
+	if x > y {
+		return x
+	}
+	return y
+}
+
+func min64(x, y int64) int64 {
+	if x < y {
+		return x
+	}
+	return y
+}
+
+func maxf(x, y float64) float64 {
+	if x > y {
+		return x
+	}
+	return y
+}
+
+func minf(x, y float64) float64 {
+	if x < y {
+		return x
+	}
+	return y
+}
@@ -0,0 +1,63 @@
+package fish
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeManagerBudget(t *testing.T) {
+	// A minute for the rest of the game: a 30th of it, and up to four
+	// times that
+	tm := new_time_manager(60000, 0, 0, 0)
+	if tm.soft != 2000 || tm.hard != 8000 {
+		t.Errorf("sudden death: soft %d, hard %d", tm.soft, tm.hard)
+	}
+
+	// The last move before the time control may use the whole clock, but
+	// the overhead
+	tm = new_time_manager(60000, 0, 1, 0)
+	if want := int64(60000 - setting_move_overhead); tm.soft != want || tm.hard != want {
+		t.Errorf("one move to go: soft %d, hard %d", tm.soft, tm.hard)
+	}
+
+	// An empty clock spends the increment, or the overhead without one
+	tm = (&Limits{WhiteInc: 5 * time.Second, BlackTime: time.Second, Clock: true}).time_manager(true)
+	if tm == nil || tm.soft != 3750 || tm.hard != int64(5000-setting_move_overhead) {
+		t.Errorf("increment only: %+v", tm)
+	}
+	tm = (&Limits{BlackTime: time.Second, Clock: true}).time_manager(true)
+	if overhead := int64(setting_move_overhead); tm == nil || tm.soft != overhead || tm.hard != overhead {
+		t.Errorf("no time left: %+v", tm)
+	}
+
+	// With no clock at all we pick our own
+	if tm := (&Limits{}).time_manager(true); tm == nil || tm.hard <= int64(setting_move_overhead) {
+		t.Errorf("no clock: %+v", tm)
+	}
+	if tm := (&Limits{Depth: 3}).time_manager(true); tm != nil {
+		t.Errorf("depth only: %+v", tm)
+	}
+}
+
+// A best move that changes late gets more time than a stable one.
+func TestTimeManagerInstability(t *testing.T) {
+	e2e4, _ := parseMove("e2e4")
+	d2d4, _ := parseMove("d2d4")
+
+	stop := func(last Move) bool {
+		tm := new_time_manager(60000, 0, 0, 0)
+		for depth, elapsed := range []int64{100, 300, 700} {
+			if tm.stop_after(search_result{depth: depth + 1, move: e2e4}, elapsed) {
+				t.Fatalf("stopped at depth %d", depth+1)
+			}
+		}
+		return tm.stop_after(search_result{depth: 4, move: last}, 2100)
+	}
+
+	if !stop(e2e4) {
+		t.Errorf("a stable move should stop past the soft limit")
+	}
+	if stop(d2d4) {
+		t.Errorf("a new move should get more time")
+	}
+}
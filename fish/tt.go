@@ -0,0 +1,224 @@
+package fish
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// Entries per bucket. A position always lands in the same bucket, whatever
+// its depth, so the best move of any depth can be found there.
+const tt_bucket_entries = 4
+
+// tt_entry holds the bounds of one (position, depth, flag) search, and the
+// best move found by it. flag is a part of the key each strategy picks, to
+// keep apart searches of the same depth that look at different moves. A
+// zero generation marks an empty slot.
+type tt_entry struct {
+	key          uint64
+	lower, upper int32
+	move         uint32 // packed, see pack_move; 0 when there is none
+	depth        int16
+	flag         bool
+	generation   uint8
+}
+
+// tt_slot stores a tt_entry in two words, plus the key xored with both of
+// them. Workers read and write slots without locking: a slot torn by two
+// concurrent writes fails the key check, and just looks like a miss.
+type tt_slot struct {
+	check, data1, data2 uint64
+}
+
+func (self *tt_slot) load() tt_entry {
+	check := atomic.LoadUint64(&self.check)
+	data1 := atomic.LoadUint64(&self.data1)
+	data2 := atomic.LoadUint64(&self.data2)
+
+	return tt_entry{
+		key:        check ^ data1 ^ data2,
+		lower:      int32(uint32(data1)),
+		upper:      int32(uint32(data1 >> 32)),
+		move:       uint32(data2 & 0xffffff),
+		depth:      int16(uint16(data2 >> 24)),
+		flag:       data2>>40&1 != 0,
+		generation: uint8(data2 >> 48),
+	}
+}
+
+func (self *tt_slot) save(e tt_entry) {
+	data1 := uint64(uint32(e.lower)) | uint64(uint32(e.upper))<<32
+	data2 := uint64(e.move) | uint64(uint16(e.depth))<<24 | uint64(e.generation)<<48
+	if e.flag {
+		data2 |= 1 << 40
+	}
+
+	atomic.StoreUint64(&self.data1, data1)
+	atomic.StoreUint64(&self.data2, data2)
+	atomic.StoreUint64(&self.check, e.key^data1^data2)
+}
+
+type tt_bucket [tt_bucket_entries]tt_slot
+
+// transposition_table is a fixed-size table shared by all the workers of a
+// search. When a bucket is full the shallowest entry is replaced, with
+// entries from older searches counted as shallower.
+type transposition_table struct {
+	buckets []tt_bucket
+
+	// generation only changes between searches.
+	generation uint8
+}
+
+func new_transposition_table(mb int) *transposition_table {
+	count := max(1, (mb<<20)/int(unsafe.Sizeof(tt_bucket{})))
+	return &transposition_table{
+		buckets:    make([]tt_bucket, count),
+		generation: 1,
+	}
+}
+
+// clear empties the table, keeping its memory. No search may be running.
+func (self *transposition_table) clear() {
+	for i := range self.buckets {
+		self.buckets[i] = tt_bucket{}
+	}
+	self.generation = 1
+}
+
+// new_search ages every entry by one search.
+func (self *transposition_table) new_search() {
+	self.generation++
+	if self.generation == 0 {
+		self.generation = 1
+	}
+}
+
+func (self *transposition_table) bucket(hash uint64) *tt_bucket {
+	return &self.buckets[hash%uint64(len(self.buckets))]
+}
+
+func (self *transposition_table) probe_score(hash uint64, depth int, flag bool) (bounds, bool) {
+	bucket := self.bucket(hash)
+	for i := range bucket {
+		e := bucket[i].load()
+		if e.generation != 0 && e.key == hash && int(e.depth) == depth && e.flag == flag {
+			return bounds{int(e.lower), int(e.upper)}, true
+		}
+	}
+	return bounds{}, false
+}
+
+// probe_move returns the best move from the deepest search of the position.
+func (self *transposition_table) probe_move(hash uint64) (Move, bool) {
+	bucket := self.bucket(hash)
+	found := false
+	var best tt_entry
+	for i := range bucket {
+		e := bucket[i].load()
+		if e.generation != 0 && e.key == hash && e.move != 0 && (!found || e.depth > best.depth) {
+			best = e
+			found = true
+		}
+	}
+	if !found {
+		return Move{}, false
+	}
+	return unpack_move(best.move), true
+}
+
+// entry finds the slot for (hash, depth, flag), choosing one to replace when
+// it is not in the table yet. The caller saves the updated entry.
+func (self *transposition_table) entry(hash uint64, depth int, flag bool) (*tt_slot, tt_entry) {
+	bucket := self.bucket(hash)
+
+	var entries [tt_bucket_entries]tt_entry
+	for i := range bucket {
+		e := bucket[i].load()
+		if e.generation != 0 && e.key == hash && int(e.depth) == depth && e.flag == flag {
+			e.generation = self.generation
+			return &bucket[i], e
+		}
+		entries[i] = e
+	}
+
+	// A new depth starts from the best move known so far, so the move
+	// survives when older depths of the position are replaced.
+	move, _ := self.probe_move(hash)
+
+	replace := 0
+	for i := range entries {
+		if entries[i].generation == 0 {
+			replace = i
+			break
+		}
+		if self.worth(entries[i]) < self.worth(entries[replace]) {
+			replace = i
+		}
+	}
+
+	return &bucket[replace], tt_entry{
+		key:        hash,
+		lower:      -MATE_UPPER,
+		upper:      MATE_UPPER,
+		depth:      int16(depth),
+		move:       pack_move(move),
+		flag:       flag,
+		generation: self.generation,
+	}
+}
+
+// worth ranks entries for replacement: deep ones from recent searches stay.
+func (self *transposition_table) worth(e tt_entry) int {
+	age := int(self.generation - e.generation)
+	return int(e.depth) - 8*age
+}
+
+func (self *transposition_table) store_score(hash uint64, depth int, flag bool, entry bounds) {
+	slot, e := self.entry(hash, depth, flag)
+	e.lower = int32(entry.lower)
+	e.upper = int32(entry.upper)
+	slot.save(e)
+}
+
+// store_move records the best move of a search; an empty move means the
+// null move or standing pat was best.
+func (self *transposition_table) store_move(hash uint64, depth int, flag bool, move Move) {
+	slot, e := self.entry(hash, depth, flag)
+	e.move = pack_move(move)
+	slot.save(e)
+}
+
+// forget drops everything known about the position.
+func (self *transposition_table) forget(hash uint64) {
+	bucket := self.bucket(hash)
+	for i := range bucket {
+		if bucket[i].load().key == hash {
+			bucket[i].save(tt_entry{})
+		}
+	}
+}
+
+// hashfull is the permille of sampled entries written by the current search.
+func (self *transposition_table) hashfull() int {
+	used, total := 0, 0
+	for i := 0; i < len(self.buckets) && total < 1000; i++ {
+		for j := range self.buckets[i] {
+			if self.buckets[i][j].load().generation == self.generation {
+				used++
+			}
+			total++
+		}
+	}
+	return used * 1000 / total
+}
+
+func pack_move(m Move) uint32 {
+	if m == (Move{}) {
+		return 0
+	}
+	return uint32(m.from) | uint32(m.to)<<8 | uint32(m.prom)<<16
+}
+
+func unpack_move(m uint32) Move {
+	return Move{int(m & 0xff), int(m >> 8 & 0xff), int(m >> 16 & 0xff)}
+}
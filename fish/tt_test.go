@@ -0,0 +1,73 @@
+package fish
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestTranspositionTable(t *testing.T) {
+	tt := new_transposition_table(1)
+	buckets := len(tt.buckets)
+	move := Move{a1 + north, a1 + 2*north, 0}
+
+	tt.store_score(42, 3, false, bounds{-10, 20})
+	tt.store_move(42, 3, false, move)
+	if e, ok := tt.probe_score(42, 3, false); !ok || e != (bounds{-10, 20}) {
+		t.Errorf("probe_score = %v %v", e, ok)
+	}
+	if _, ok := tt.probe_score(42, 3, true); ok {
+		t.Errorf("root and non-root entries are mixed up")
+	}
+	if m, ok := tt.probe_move(42); !ok || m != move {
+		t.Errorf("probe_move = %v %v", m, ok)
+	}
+
+	// Fill the bucket with deeper entries of other positions: the
+	// shallowest one goes first.
+	for i := 1; i <= tt_bucket_entries; i++ {
+		tt.store_score(42+uint64(i*buckets), 3+i, false, bounds{0, 0})
+	}
+	if _, ok := tt.probe_score(42, 3, false); ok {
+		t.Errorf("shallowest entry was kept")
+	}
+
+	// Entries from older searches make room for new ones, however deep.
+	tt.new_search()
+	tt.store_score(42, 0, false, bounds{1, 2})
+	if _, ok := tt.probe_score(42, 0, false); !ok {
+		t.Errorf("new entry was not stored")
+	}
+
+	tt.clear()
+	if len(tt.buckets) != buckets || tt.hashfull() != 0 {
+		t.Errorf("clear should empty the table in place")
+	}
+	if _, ok := tt.probe_move(42); ok {
+		t.Errorf("clear left a move behind")
+	}
+}
+
+// Workers share the table without locks: a reader must see either nothing
+// or a whole entry, never the halves of two.
+func TestTranspositionTableConcurrent(t *testing.T) {
+	tt := new_transposition_table(1)
+	buckets := uint64(len(tt.buckets))
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 10000; i++ {
+				hash := 7 + uint64(i%8)*buckets
+				value := w*10000 + i
+				tt.store_score(hash, i%3, false, bounds{value, value})
+				if e, ok := tt.probe_score(hash, i%3, false); ok && e.lower != e.upper {
+					t.Errorf("torn entry %v", e)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
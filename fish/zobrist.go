@@ -0,0 +1,86 @@
+package fish
+
+// Zobrist keys. The board is always seen from the side to move, so every
+// Position carries two hashes: hash for the board as it is, and rhash for
+// the rotated board. rotate() only has to swap them.
+var zobrist_board [piece_is_empty + 1][120]uint64 // the empty row stays zero
+var zobrist_wc, zobrist_bc [2]uint64
+var zobrist_ep, zobrist_kp [120]uint64
+
+func init() {
+	// splitmix64 with a fixed seed, so hashes are the same on every run
+	seed := uint64(0x9E3779B97F4A7C15)
+	next := func() uint64 {
+		seed += 0x9E3779B97F4A7C15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+		z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+		return z ^ (z >> 31)
+	}
+
+	for p := 0; p < piece_is_empty; p++ {
+		for sq := 0; sq < 120; sq++ {
+			zobrist_board[p][sq] = next()
+		}
+	}
+	for i := 0; i < 2; i++ {
+		zobrist_wc[i] = next()
+		zobrist_bc[i] = next()
+	}
+	for sq := 0; sq < 120; sq++ {
+		zobrist_ep[sq] = next()
+		zobrist_kp[sq] = next()
+	}
+}
+
+// zobrist_square returns the keys of piece p on sq, as is and rotated.
+func zobrist_square(p piece, sq int) (uint64, uint64) {
+	return zobrist_board[p][sq], zobrist_board[p.swapcase()][119-sq]
+}
+
+// zobrist_rights returns the keys of the castling rights and the ep and kp
+// squares, as is and rotated.
+func zobrist_rights(wc, bc [2]bool, ep, kp int) (uint64, uint64) {
+	var hash, rhash uint64
+
+	for i := 0; i < 2; i++ {
+		if wc[i] {
+			hash ^= zobrist_wc[i]
+			rhash ^= zobrist_bc[i]
+		}
+		if bc[i] {
+			hash ^= zobrist_bc[i]
+			rhash ^= zobrist_wc[i]
+		}
+	}
+	if ep != 0 {
+		hash ^= zobrist_ep[ep]
+		rhash ^= zobrist_ep[119-ep]
+	}
+	if kp != 0 {
+		hash ^= zobrist_kp[kp]
+		rhash ^= zobrist_kp[119-kp]
+	}
+
+	return hash, rhash
+}
+
+// compute_hash builds the hashes and the pawn hashes from scratch. move(),
+// rotate() and nullmove() keep them up to date incrementally.
+func (self *Position) compute_hash() (hash, rhash, pawn_hash, pawn_rhash uint64) {
+	hash, rhash = zobrist_rights(self.wc, self.bc, self.ep, self.kp)
+
+	for sq, p := range self.board {
+		if p.isupper() || p.islower() {
+			h, r := zobrist_square(p, sq)
+			hash ^= h
+			rhash ^= r
+			if p.is_pawn() {
+				pawn_hash ^= h
+				pawn_rhash ^= r
+			}
+		}
+	}
+
+	return hash, rhash, pawn_hash, pawn_rhash
+}
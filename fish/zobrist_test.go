@@ -0,0 +1,58 @@
+package fish
+
+import "testing"
+
+func checkHashes(t *testing.T, pos *Position, depth int) {
+	hash, rhash, pawn_hash, pawn_rhash := pos.compute_hash()
+	if pos.hash != hash || pos.rhash != rhash {
+		t.Fatalf("%s: incremental hash differs from a fresh one", pos.fen())
+	}
+	if pos.pawn_hash != pawn_hash || pos.pawn_rhash != pawn_rhash {
+		t.Fatalf("%s: incremental pawn hash differs from a fresh one", pos.fen())
+	}
+	if depth == 0 {
+		return
+	}
+
+	null := pos.nullmove()
+	if hash, _, pawn_hash, _ := null.compute_hash(); null.hash != hash || null.pawn_hash != pawn_hash {
+		t.Fatalf("%s: null move hash differs from a fresh one", pos.fen())
+	}
+
+	pos.gen_moves(func(m Move) bool {
+		checkHashes(t, pos.move(m), depth-1)
+		return false
+	})
+}
+
+func TestZobristIncremental(t *testing.T) {
+	for _, p := range perftPositions {
+		pos, err := parseFEN(p.fen)
+		if err != nil {
+			t.Fatalf("%s: %s", p.name, err)
+		}
+		checkHashes(t, pos, 3)
+	}
+}
+
+func TestZobristTransposition(t *testing.T) {
+	start, _ := parseFEN(FEN_INITIAL)
+
+	pos := start
+	for _, str := range []string{"g1f3", "g8f6", "f3g1", "f6g8"} {
+		move, _ := parseMove(str)
+		if !pos.white_turn() {
+			move = move.rotate()
+		}
+		pos = pos.move(move)
+	}
+
+	if pos.hash != start.hash {
+		t.Errorf("same position after a knight dance hashes differently")
+	}
+
+	moved, _ := parseFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	if moved.hash == start.hash || moved.hash == start.rotate().hash {
+		t.Errorf("different positions share a hash")
+	}
+}
@@ -0,0 +1,169 @@
+// +build !wasm
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kargeor/golang-fish/fish"
+)
+
+const (
+	OPTION_SPIN   = "spin"
+	OPTION_BUTTON = "button"
+	OPTION_STRING = "string"
+	OPTION_COMBO  = "combo"
+	OPTION_CHECK  = "check"
+)
+
+type UciOption struct {
+	name          string
+	kind          string
+	def, min, max int
+	vars          []string // for combo, the first one is the default
+	spin          func(value int)
+	button        func()
+	check         func(value bool)
+	def_check     bool
+	str           func(value string) error // for string and combo
+}
+
+func uciOptions(searcher *fish.Searcher) []*UciOption {
+	options := []*UciOption{
+		{
+			name: "Hash", kind: OPTION_SPIN, def: fish.DEFAULT_HASH_MB, min: 1, max: 65536,
+			spin: func(value int) { searcher.SetHashSize(value) },
+		},
+		{
+			name: "Threads", kind: OPTION_SPIN, def: 1, min: 1, max: 256,
+			spin: func(value int) { searcher.SetThreads(value) },
+		},
+		{
+			name: "Clear Hash", kind: OPTION_BUTTON,
+			button: func() { searcher.Clear() },
+		},
+		{
+			name: "MultiPV", kind: OPTION_SPIN, def: 1, min: 1, max: 256,
+			spin: func(value int) { searcher.SetMultiPV(value) },
+		},
+		{
+			name: "Strategy", kind: OPTION_COMBO, vars: fish.STRATEGIES,
+			str: func(value string) error { return searcher.SetStrategy(value) },
+		},
+		{
+			name: "EvalFile", kind: OPTION_STRING,
+			str: func(value string) error {
+				if err := fish.LoadEval(value); err != nil {
+					return err
+				}
+				// Scores of the old evaluation
+				searcher.Clear()
+				return nil
+			},
+		},
+	}
+
+	// The knobs of the engine, as they are named in it
+	for _, setting := range fish.Settings() {
+		name := setting.Name
+		option := &UciOption{
+			name: name, kind: OPTION_SPIN, def: setting.Default, min: setting.Min, max: setting.Max,
+			spin: func(value int) { fish.SetSetting(name, value) },
+		}
+		if setting.Check {
+			option.kind = OPTION_CHECK
+			option.def_check = setting.Default != 0
+			option.check = func(value bool) {
+				if value {
+					fish.SetSetting(name, 1)
+				} else {
+					fish.SetSetting(name, 0)
+				}
+			}
+		}
+		options = append(options, option)
+	}
+
+	return options
+}
+
+func (self *UciOption) String() string {
+	switch self.kind {
+	case OPTION_SPIN:
+		return fmt.Sprintf("option name %s type spin default %d min %d max %d", self.name, self.def, self.min, self.max)
+	case OPTION_STRING:
+		return fmt.Sprintf("option name %s type string default <empty>", self.name)
+	case OPTION_CHECK:
+		return fmt.Sprintf("option name %s type check default %t", self.name, self.def_check)
+	case OPTION_COMBO:
+		return fmt.Sprintf("option name %s type combo default %s var %s", self.name, self.vars[0], strings.Join(self.vars, " var "))
+	}
+
+	return fmt.Sprintf("option name %s type %s", self.name, self.kind)
+}
+
+func (self *UciOption) set(value string) error {
+	switch self.kind {
+	case OPTION_SPIN:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("option %s needs a number [%s]", self.name, value)
+		}
+		if v < self.min || v > self.max {
+			return fmt.Errorf("option %s must be between %d and %d [%d]", self.name, self.min, self.max, v)
+		}
+		self.spin(v)
+	case OPTION_BUTTON:
+		self.button()
+	case OPTION_STRING:
+		if value == "<empty>" {
+			value = ""
+		}
+		return self.str(value)
+	case OPTION_COMBO:
+		return self.str(value)
+	case OPTION_CHECK:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("option %s needs true or false [%s]", self.name, value)
+		}
+		self.check(v)
+	}
+
+	return nil
+}
+
+// setOption handles "setoption name <id> [value <x>]". Option names may
+// contain spaces and are matched case-insensitively.
+func setOption(options []*UciOption, command string) error {
+	parts := strings.Fields(command)
+	name_at, value_at := -1, len(parts)
+	for i, part := range parts {
+		if part == "name" && name_at < 0 {
+			name_at = i
+		}
+		if part == "value" && name_at >= 0 && value_at == len(parts) {
+			value_at = i
+		}
+	}
+
+	if name_at < 0 {
+		return fmt.Errorf("setoption without name [%s]", command)
+	}
+
+	name := strings.Join(parts[name_at+1:value_at], " ")
+	value := ""
+	if value_at < len(parts) {
+		value = strings.Join(parts[value_at+1:], " ")
+	}
+
+	for _, option := range options {
+		if strings.EqualFold(option.name, name) {
+			return option.set(value)
+		}
+	}
+
+	return fmt.Errorf("unknown option [%s]", name)
+}
@@ -0,0 +1,308 @@
+// +build !wasm
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kargeor/golang-fish/fish"
+)
+
+// uciSearch is a search running in its own goroutine, so that the command
+// loop can still answer "isready" and act on "stop" or "ponderhit".
+type uciSearch struct {
+	done      chan struct{}
+	cancel    context.CancelFunc
+	ponderhit chan struct{}
+}
+
+func readCommands(reader *bufio.Reader) <-chan string {
+	commands := make(chan string)
+
+	go func() {
+		for {
+			command, err := reader.ReadString('\n')
+			command = strings.TrimSpace(command)
+			if command != "" {
+				commands <- command
+			}
+			if err != nil {
+				close(commands)
+				return
+			}
+		}
+	}()
+
+	return commands
+}
+
+func uciLoop(searcher *fish.Searcher, reader *bufio.Reader) {
+	// pos is nil after a "position" we couldn't follow, until the next one
+	pos, _ := fish.NewPosition(fish.FEN_INITIAL)
+	options := uciOptions(searcher)
+	var current *uciSearch
+
+	stop := func() {
+		if current != nil {
+			current.cancel()
+			<-current.done
+			current = nil
+		}
+	}
+	defer stop()
+
+	for command := range readCommands(reader) {
+		switch {
+		case strings.HasPrefix(command, "quit"):
+			return
+		case strings.HasPrefix(command, "stop"):
+			stop()
+		case strings.HasPrefix(command, "ponderhit"):
+			if current != nil && current.ponderhit != nil {
+				close(current.ponderhit)
+				current.ponderhit = nil
+			}
+		case strings.HasPrefix(command, "ucinewgame"):
+			stop()
+			searcher.Clear()
+			pos, _ = fish.NewPosition(fish.FEN_INITIAL)
+		case strings.HasPrefix(command, "uci"):
+			fmt.Printf("id name GoLangFish\n")
+			fmt.Printf("id author kargeor & Sunfish Contributors\n")
+			for _, option := range options {
+				fmt.Printf("%s\n", option)
+			}
+			fmt.Printf("uciok\n")
+		case strings.HasPrefix(command, "setoption"):
+			stop()
+			if err := setOption(options, command); err != nil {
+				fmt.Printf("info string %s\n", err)
+			}
+		case strings.HasPrefix(command, "isready"):
+			fmt.Printf("readyok\n")
+		case strings.HasPrefix(command, "position"):
+			stop()
+			parts := strings.Fields(command)
+			for i := 1; i < len(parts); i++ {
+				part := parts[i]
+				switch {
+				case strings.HasPrefix(part, "startpos"):
+					pos, _ = fish.NewPosition(fish.FEN_INITIAL)
+				case strings.HasPrefix(part, "moves"):
+					for i++; i < len(parts) && pos != nil; i++ {
+						move, err := fish.ParseMove(parts[i])
+						if err == nil {
+							var next *fish.Position
+							if next, err = pos.Play(move); err == nil {
+								pos = next
+								continue
+							}
+						}
+
+						// The rest of the list makes no sense from here
+						fmt.Printf("info string %s\n", err)
+						pos = nil
+						i = len(parts)
+					}
+				case strings.HasPrefix(part, "fen"):
+					fields := []string{}
+					for i+1 < len(parts) && parts[i+1] != "moves" {
+						i++
+						fields = append(fields, parts[i])
+					}
+
+					fen_pos, err := fish.NewPosition(strings.Join(fields, " "))
+					if err != nil {
+						fmt.Printf("info string Failed to parse FEN: %s\n", err)
+						pos = nil
+						i = len(parts)
+						break
+					}
+
+					pos = fen_pos
+				}
+			}
+		case strings.HasPrefix(command, "go perft"):
+			stop()
+			parts := strings.Fields(command)
+			depth := 0
+			if len(parts) > 2 {
+				depth, _ = strconv.Atoi(parts[2])
+			}
+			if depth < 1 {
+				fmt.Printf("info string go perft needs a depth\n")
+				break
+			}
+			if pos == nil {
+				fmt.Printf("info string No valid position\n")
+				break
+			}
+			divide(pos, depth)
+		case strings.HasPrefix(command, "go"):
+			stop()
+			if pos == nil {
+				fmt.Printf("info string No valid position\n")
+				fmt.Printf("bestmove (none)\n")
+				break
+			}
+			current = uciGo(searcher, pos, command)
+		}
+	}
+}
+
+// divide prints the perft count below each move, in the format most engines
+// use.
+func divide(pos *fish.Position, depth int) {
+	total := pos.Divide(depth, func(m fish.Move, nodes int) {
+		fmt.Printf("%s: %d\n", m, nodes)
+	})
+	fmt.Printf("\nNodes searched: %d\n", total)
+}
+
+var goKeywords = []string{"wtime", "btime", "winc", "binc", "movestogo", "movetime", "depth", "nodes", "mate", "infinite", "ponder", "searchmoves"}
+
+func parseGo(command string) fish.Limits {
+	limits := fish.Limits{}
+	parts := strings.Fields(command)
+
+	number := func(i int) int {
+		if i >= len(parts) {
+			fmt.Printf("info string Missing value for [%s]\n", parts[i-1])
+			return 0
+		}
+		value, err := strconv.Atoi(parts[i])
+		if err != nil {
+			fmt.Printf("info string Bad value for [%s]: %s\n", parts[i-1], parts[i])
+		}
+		return value
+	}
+	ms := func(i int) time.Duration {
+		return time.Duration(number(i)) * time.Millisecond
+	}
+
+	for i := 1; i < len(parts); i++ {
+		switch parts[i] {
+		case "wtime":
+			i++
+			limits.WhiteTime = ms(i)
+			limits.Clock = true
+		case "btime":
+			i++
+			limits.BlackTime = ms(i)
+			limits.Clock = true
+		case "winc":
+			i++
+			limits.WhiteInc = ms(i)
+		case "binc":
+			i++
+			limits.BlackInc = ms(i)
+		case "movestogo":
+			i++
+			limits.MovesToGo = number(i)
+		case "movetime":
+			i++
+			limits.MoveTime = ms(i)
+		case "depth":
+			i++
+			limits.Depth = number(i)
+		case "nodes":
+			i++
+			limits.Nodes = number(i)
+		case "mate":
+			i++
+			limits.Mate = number(i)
+		case "infinite":
+			limits.Infinite = true
+		case "ponder":
+			limits.Ponder = true
+		case "searchmoves":
+			for i+1 < len(parts) && !contains(goKeywords, parts[i+1]) {
+				i++
+				move, err := fish.ParseMove(parts[i])
+				if err != nil {
+					fmt.Printf("info string %s\n", err)
+					continue
+				}
+				limits.SearchMoves = append(limits.SearchMoves, move)
+			}
+		default:
+			fmt.Printf("info string Unknown go parameter [%s]\n", parts[i])
+		}
+	}
+
+	return limits
+}
+
+func contains(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
+func uciGo(searcher *fish.Searcher, pos *fish.Position, command string) *uciSearch {
+	limits := parseGo(command)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &uciSearch{
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+	if limits.Ponder {
+		job.ponderhit = make(chan struct{})
+		limits.PonderHit = job.ponderhit
+	}
+
+	go func() {
+		defer close(job.done)
+
+		best := searcher.Search(ctx, pos, limits, func(info fish.Info) {
+			if info.Bound != fish.BOUND_EXACT {
+				// Only long iterations are worth reporting step by step
+				if info.Time >= time.Second {
+					fmt.Printf("info depth %d multipv %d score %s nodes %d time %d\n", info.Depth, info.MultiPV, uciScore(info), info.Nodes, info.Time.Milliseconds())
+				}
+				return
+			}
+
+			if info.Depth == 0 {
+				// No legal moves
+				fmt.Printf("info depth 0 score %s\n", uciScore(info))
+				return
+			}
+
+			fmt.Printf("info depth %d multipv %d score %s nodes %d time %d hashfull %d pv %s\n", info.Depth, info.MultiPV, uciScore(info), info.Nodes, info.Time.Milliseconds(), info.HashFull, fish.FormatMoves(info.PV))
+		})
+
+		if best.Move == (fish.Move{}) {
+			fmt.Printf("bestmove (none)\n")
+		} else {
+			fmt.Printf("bestmove %s\n", best.Move)
+		}
+	}()
+
+	return job
+}
+
+func uciScore(info fish.Info) string {
+	str := fmt.Sprintf("cp %d", info.Score)
+	if moves, ok := info.Mate(); ok {
+		str = fmt.Sprintf("mate %d", moves)
+	}
+
+	switch info.Bound {
+	case fish.BOUND_LOWER:
+		str += " lowerbound"
+	case fish.BOUND_UPPER:
+		str += " upperbound"
+	}
+
+	return str
+}
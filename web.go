@@ -3,12 +3,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"syscall/js"
-	"time"
-)
 
-const initial = "                     rnbqkbnr  pppppppp  ........  ........  ........  ........  PPPPPPPP  RNBQKBNR                     "
+	"github.com/kargeor/golang-fish/fish"
+)
 
 const (
 	CLICK_SQUARE = iota
@@ -23,9 +23,9 @@ type Event struct {
 
 var document, chessboardDiv, logDiv js.Value
 var squareDivs []js.Value
-var pos *Position
-var searcher *Searcher
-var moveFrom = 0
+var pos *fish.Position
+var searcher *fish.Searcher
+var moveFrom = ""
 var events = make(chan Event)
 var spinner js.Value
 
@@ -52,16 +52,22 @@ func addClickHandler(div js.Value, event Event) {
 	div.Call("addEventListener", "click", cb)
 }
 
-func updateChessBoard(pos *Position) {
+// squareName names the square of row i and column j of the board, with a8
+// at the top left.
+func squareName(i, j int) string {
+	return fmt.Sprintf("%c%d", 'a'+j, 8-i)
+}
+
+func updateChessBoard(pos *fish.Position) {
 	divCounter := 0
 
-	for i := A8; i <= H1; i += S {
+	for i := 0; i < 8; i++ {
 		for j := 0; j < 8; j++ {
-			v := pos.board[i+j]
-			if v == PIECE_IS_EMPTY {
+			v := pos.PieceAt(squareName(i, j))
+			if v == "" {
 				squareDivs[divCounter].Set("innerHTML", "&nbsp;")
 			} else {
-				squareDivs[divCounter].Set("innerText", v.String())
+				squareDivs[divCounter].Set("innerText", v)
 			}
 			divCounter++
 		}
@@ -87,76 +93,81 @@ func clearSelected() {
 }
 
 func squareClickHandler(i, j int) {
-	if pos.score <= -MATE_LOWER {
-		log("You lost")
+	if len(pos.LegalMoves()) == 0 {
+		if pos.InCheck() {
+			log("You lost")
+		} else {
+			log("Stalemate")
+		}
 		return
 	}
 
-	if moveFrom == 0 {
-		moveFrom = A8 + i*S + j*E
+	if moveFrom == "" {
+		moveFrom = squareName(i, j)
 		available := 0
 
-		pos.gen_moves(func(m Move) bool {
-			if m[0] == moveFrom {
+		for _, m := range pos.LegalMoves() {
+			if m.String()[0:2] == moveFrom {
 				available++
 
-				i1 := (m[1] - A8) / 10
-				j1 := (m[1] - A8) % 10
+				to := m.String()[2:4]
+				i1 := int('8' - to[1])
+				j1 := int(to[0] - 'a')
 
 				squareDivs[i1*8+j1].Set("className", "selected")
 			}
-			return false
-		})
+		}
 
 		log(fmt.Sprintf("Moves available %d\n", available))
 	} else {
-		moveTo := A8 + i*S + j*E
+		moveTo := squareName(i, j)
 		move_valid := false
-		var move Move
+		var move fish.Move
 
-		pos.gen_moves(func(m Move) bool {
-			if m[0] == moveFrom && m[1] == moveTo {
+		// Promotions come queen first
+		for _, m := range pos.LegalMoves() {
+			if m.String()[0:4] == moveFrom+moveTo {
 				move_valid = true
 				move = m
-				return true
+				break
 			}
-			return false
-		})
+		}
 
-		moveFrom = 0
+		moveFrom = ""
 		clearSelected()
 
 		waitForJs()
 
 		if move_valid {
-			pos = pos.move(move)
-			rotated := pos.rotate()
-			updateChessBoard(rotated)
+			pos, _ = pos.Play(move)
+			updateChessBoard(pos)
 
 			setSpinnerVisible(true)
 			waitForJs()
 
-			if pos.score <= -MATE_LOWER {
-				log("You won!")
+			if len(pos.LegalMoves()) == 0 {
+				if pos.InCheck() {
+					log("You won!")
+				} else {
+					log("Stalemate")
+				}
 				setSpinnerVisible(false)
 				return
 			}
 
-			start := time.Now()
-			var bestResult SearchResult
-			searcher.search(pos, func(r SearchResult) bool {
-				elapsed := time.Since(start)
-				log(fmt.Sprintf("(%s) depth=%d score=%d move=[%s]\n", elapsed, r.depth, r.score, r.move.rotate()))
+			best := searcher.Search(context.Background(), pos, fish.Limits{Depth: 7}, func(info fish.Info) {
+				if info.Bound != fish.BOUND_EXACT {
+					return
+				}
+				log(fmt.Sprintf("(%s) depth=%d score=%d move=[%s] pv=[%s]\n", info.Time, info.Depth, info.Score, info.Move, fish.FormatMoves(info.PV)))
 				waitForJs()
-				bestResult = r
-				return r.depth >= 7
 			})
 
-			if bestResult.score == MATE_UPPER {
-				log("Checkmate!")
+			if moves, ok := best.Mate(); ok && moves > 0 {
+				log(fmt.Sprintf("Checkmate in %d!", moves))
 			}
 
-			pos = pos.move(bestResult.move)
+			pos, _ = pos.Play(best.Move)
 			updateChessBoard(pos)
 			setSpinnerVisible(false)
 		}
@@ -164,30 +175,14 @@ func squareClickHandler(i, j int) {
 }
 
 func newGame(playFirst bool) {
-	var initial_board Board
-	for i := 0; i < 120; i++ {
-		initial_board[i] = MakePiece(initial[i])
-	}
+	pos, _ = fish.NewPosition(fish.FEN_INITIAL)
 
-	pos = &Position{
-		board: initial_board,
-		score: 0,
-		wc:    [2]bool{true, true},
-		bc:    [2]bool{true, true},
-		ep:    0,
-		kp:    0,
-	}
-
-	searcher = NewSearcher()
+	// One table for the whole page, emptied for each game
+	searcher.Clear()
 
 	if playFirst {
-		var bestResult SearchResult
-		searcher.search(pos, func(r SearchResult) bool {
-			bestResult = r
-			return true
-		})
-
-		pos = pos.move(bestResult.move)
+		best := searcher.Search(context.Background(), pos, fish.Limits{Depth: 1}, func(info fish.Info) {})
+		pos, _ = pos.Play(best.Move)
 	}
 
 	updateChessBoard(pos)
@@ -212,6 +207,7 @@ func main() {
 	addClickHandler(getElementById("playB"), Event{event_type: CLICK_NEW_GAME_BLACK})
 	addClickHandler(getElementById("undoMove"), Event{event_type: CLICK_UNDO})
 
+	searcher = fish.NewSearcher()
 	newGame(false)
 
 	// wait for events